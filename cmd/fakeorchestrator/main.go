@@ -0,0 +1,146 @@
+// Fakeorchestrator - сервер Orchestrator в памяти для локальной разработки раннера.
+//
+// Работы читаются из каталога <works>/<id события>/<id работы>/, для каждой работы создаётся новая задача.
+// Вызовы методов и полученные отчёты выводятся в stdout.
+//
+// Пример: fakeorchestrator --addr 127.0.0.1:50051 --works ./testdata/works --key secret
+package main
+
+import (
+	"CodeBorrowing/internal/orchestratortest"
+	"CodeBorrowing/services/orchestrator"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"syscall"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:50051", "адрес сервера grpc (mainServerHost раннера)")
+	works := flag.String("works", "", "каталог работ <id события>/<id работы>/")
+	key := flag.String("key", "", "ключ авторизации (mainServerKey раннера, пусто - не проверяется)")
+	tag := flag.String("tag", "", "тег задач")
+	runnerTag := flag.String("runner-tag", "", "тег раннера в GetRunnerInfo")
+	cert := flag.String("tls-cert", "", "сертификат сервера в формате PEM (пусто - без TLS)")
+	certKey := flag.String("tls-key", "", "закрытый ключ сертификата сервера в формате PEM")
+	clientCA := flag.String("client-ca", "", "сертификаты центров сертификации раннеров (пусто - сертификат раннера не требуется)")
+	tokenLifetime := flag.Duration("token-lifetime", 0, "время действия токенов авторизации (0 - ключ в каждом вызове)")
+	flag.Parse()
+
+	server := orchestratortest.NewServer()
+	server.SetKey(*key)
+	server.SetRunner(&orchestrator.Runner{ID: 1, Name: "fakeorchestrator", Tag: *runnerTag})
+	if *tokenLifetime != 0 {
+		server.EnableTokens(*tokenLifetime)
+	}
+	server.SetTrace(func(method string, req any) {
+		if message, ok := req.(proto.Message); ok {
+			content, _ := protojson.Marshal(message)
+			fmt.Printf("%s %s\n", method, content)
+		} else {
+			fmt.Println(method)
+		}
+	})
+
+	if *cert != "" {
+		tlsConfig, err := newTLSConfig(*cert, *certKey, *clientCA)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		server.SetTLS(tlsConfig)
+	}
+
+	if *works != "" {
+		if err := addWorks(server, *works, *tag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if err := server.Start(*addr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer server.Close()
+	fmt.Printf("grpc: %s, файлы: %s\n", server.Addr(), server.FilesURL())
+	if *tokenLifetime != 0 {
+		fmt.Printf("токены: %s\n", server.AuthURL())
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	for _, call := range server.CloseCalls() {
+		fmt.Printf("закрыты задачи %v (с ошибкой: %t)\n", call.ID, call.WithError)
+	}
+	fmt.Printf("получено отчётов: %d\n", len(server.Reports()))
+}
+
+// addWorks добавляет работы событий из каталога dir и задачи по ним.
+func addWorks(server *orchestratortest.Server, dir string, tag string) error {
+	events, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var taskID uint64
+	for _, event := range events {
+		eventID, err := strconv.ParseUint(event.Name(), 10, 64)
+		if err != nil || !event.IsDir() {
+			continue
+		}
+
+		works, err := os.ReadDir(path.Join(dir, event.Name()))
+		if err != nil {
+			return err
+		}
+		for _, work := range works {
+			workID, err := strconv.ParseUint(work.Name(), 10, 64)
+			if err != nil || !work.IsDir() {
+				continue
+			}
+
+			if err = server.AddWorkDir(eventID, workID, path.Join(dir, event.Name(), work.Name())); err != nil {
+				return err
+			}
+			taskID++
+			server.AddTask(taskID, eventID, workID, tag)
+		}
+	}
+
+	return nil
+}
+
+// newTLSConfig создаёт параметры TLS сервера. Если указаны центры сертификации раннеров,
+// сервер требует сертификат раннера.
+func newTLSConfig(cert string, certKey string, clientCA string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(cert, certKey)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}}
+
+	if clientCA != "" {
+		content, err := os.ReadFile(clientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("в файле %s нет сертификатов PEM", clientCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
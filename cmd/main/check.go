@@ -0,0 +1,85 @@
+package main
+
+import (
+	"CodeBorrowing/internal/app"
+	"CodeBorrowing/internal/config"
+	"CodeBorrowing/internal/export"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+)
+
+// runCheck выполняет автономную проверку каталогов с работами (подкоманда check).
+// Пример: main check --new ./new --old ./2023,./2024 --out report.json
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	newWorks := flags.String("new", "", "каталоги с новыми работами через запятую (работы в подкаталогах)")
+	oldWorks := flags.String("old", "", "каталоги со старыми работами через запятую (необязательно)")
+	out := flags.String("out", "", "файл отчёта (по умолчанию stdout)")
+	format := flags.String("format", string(export.FormatJSON), "формат отчёта: json, jsonl, csv, sarif")
+	htmlDir := flags.String("html", "", "каталог для отчётов HTML по парам работ (необязательно)")
+	workDir := flags.String("workdir", "", "каталог для временных файлов и логов (по умолчанию workdir или временный каталог)")
+	eventID := flags.Uint64("event", 0, "id события для правил игнорирования файлов (необязательно)")
+	tag := flags.String("tag", "", "тег для правил игнорирования файлов (необязательно)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := app.CheckOptions{
+		New:     splitList(*newWorks),
+		Old:     splitList(*oldWorks),
+		EventID: *eventID,
+		Tags:    splitList(*tag),
+		Output:  os.Stdout,
+		HTMLDir: *htmlDir,
+	}
+	if len(options.New) == 0 {
+		return errors.New("check: не указаны каталоги с новыми работами (--new)")
+	}
+
+	var err error
+	if options.Format, err = export.ParseFormat(*format); err != nil {
+		return err
+	}
+
+	// Чтение конфигураций.
+	cfg, err := config.GetCheckConfig()
+	if err != nil {
+		return err
+	}
+
+	// Каталог приложения.
+	if *workDir != "" {
+		cfg.WorkDir = *workDir
+	}
+	if cfg.WorkDir == "" {
+		if cfg.WorkDir, err = os.MkdirTemp("", "codeborrowing-check-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(cfg.WorkDir)
+	}
+
+	// Файл отчёта.
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		options.Output = f
+	}
+
+	return app.Check(cfg, options)
+}
+
+// splitList разбивает список значений через запятую. Пустые значения пропускаются.
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/export"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+// Переменная среды, при которой тестовый исполняемый файл работает как внешний анализатор.
+const envTestPlugin = "CODEBORROWING_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestPlugin) != "" {
+		os.Exit(runTestPlugin())
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin - внешний анализатор для тестов: сравнивает все работы каталогов запроса попарно.
+func runTestPlugin() int {
+	var request checker.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		return 1
+	}
+
+	var works []checker.PluginWork
+	for _, root := range append(append([]string{}, request.New...), request.Old...) {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return 1
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				works = append(works, checker.PluginWork{Root: root, Name: entry.Name()})
+			}
+		}
+	}
+
+	response := checker.PluginResponse{Version: checker.PluginProtocolVersion}
+	for i := range works {
+		for j := i + 1; j < len(works); j++ {
+			response.Pairs = append(response.Pairs, checker.PluginPair{Work1: works[i], Work2: works[j], Avg: 0.5, Max: 0.5})
+		}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(response); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// TestCheckSingleDir проверяет каталог с несколькими работами без старых работ (--new без --old).
+func TestCheckSingleDir(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envTestPlugin, "1")
+	t.Setenv("checkerPlugin", executable)
+	t.Setenv("checkerLang", "java")
+
+	dir := t.TempDir()
+	newDir := path.Join(dir, "new")
+	for _, name := range []string{"101", "102", "103"} {
+		if err = os.MkdirAll(path.Join(newDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(path.Join(newDir, name, "Main.java"), []byte("class Main { }\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := path.Join(dir, "report.json")
+	if err = runCheck([]string{"--new", newDir, "--out", out, "--workdir", path.Join(dir, "work")}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report export.Report
+	if err = json.Unmarshal(content, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Items) != 3 {
+		t.Errorf("пар работ в отчёте: %d, ожидалось 3", len(report.Items))
+	}
+
+	// Единственную работу не с чем сравнивать, файлы вне каталогов работ не считаются.
+	single := path.Join(dir, "single")
+	if err = os.MkdirAll(path.Join(single, "101"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path.Join(single, "Notes.java"), []byte("class Notes { }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = runCheck([]string{"--new", single, "--out", out, "--workdir", path.Join(dir, "work")})
+	if !errors.Is(err, checker.ErrNoWorks) {
+		t.Errorf("проверка единственной работы: %v, ожидалась ошибка %v", err, checker.ErrNoWorks)
+	}
+}
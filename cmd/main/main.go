@@ -4,9 +4,28 @@ import (
 	"CodeBorrowing/internal/app"
 	"CodeBorrowing/internal/config"
 	"fmt"
+	"os"
 )
 
 func main() {
+	// Автономная проверка каталогов с работами без сервера.
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		if err := runCheck(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Воспроизведение задачи из пакета.
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := runReplay(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Чтение конфигураций.
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"CodeBorrowing/internal/app"
+	"CodeBorrowing/internal/export"
+	"errors"
+	"flag"
+	"os"
+)
+
+// runReplay воспроизводит разбор результата анализа и формирование отчётов по пакету задачи (подкоманда replay).
+// Пример: main replay --bundle ./capture/12/20240101_120000_345 --requests requests.jsonl
+func runReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	bundle := flags.String("bundle", "", "каталог пакета воспроизведения задачи")
+	out := flags.String("out", "", "файл отчёта (по умолчанию stdout)")
+	format := flags.String("format", string(export.FormatJSON), "формат отчёта: json, jsonl, csv, sarif")
+	htmlDir := flags.String("html", "", "каталог для отчётов HTML по парам работ (необязательно)")
+	requests := flags.String("requests", "", "файл для отчётов, отправляемых серверу, в формате JSON Lines (необязательно)")
+	workDir := flags.String("workdir", "", "каталог для временных файлов и логов (по умолчанию временный каталог)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *bundle == "" {
+		return errors.New("replay: не указан каталог пакета (--bundle)")
+	}
+
+	options := app.ReplayOptions{
+		Bundle:  *bundle,
+		WorkDir: *workDir,
+		Output:  os.Stdout,
+		HTMLDir: *htmlDir,
+	}
+
+	var err error
+	if options.Format, err = export.ParseFormat(*format); err != nil {
+		return err
+	}
+
+	// Каталог для временных файлов.
+	if options.WorkDir == "" {
+		if options.WorkDir, err = os.MkdirTemp("", "codeborrowing-replay-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(options.WorkDir)
+	}
+
+	// Файлы отчётов.
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		options.Output = f
+	}
+	if *requests != "" {
+		f, err := os.Create(*requests)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		options.Requests = f
+	}
+
+	return app.Replay(options)
+}
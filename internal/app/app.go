@@ -1,16 +1,22 @@
 package app
 
 import (
+	"CodeBorrowing/internal/capture"
 	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/cluster"
 	"CodeBorrowing/internal/config"
+	"CodeBorrowing/internal/export"
+	"CodeBorrowing/internal/fingerprint"
 	"CodeBorrowing/internal/logger"
-	"CodeBorrowing/internal/middleware"
+	"CodeBorrowing/internal/preprocess"
 	"CodeBorrowing/internal/task"
 	"CodeBorrowing/services/orchestrator"
+	"context"
 	"fmt"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"io"
 	"path"
+	"sync/atomic"
 	"time"
 )
 
@@ -28,6 +34,12 @@ type appT struct {
 	taskStorage task.Storage
 	taskService task.Service
 	taskChecker checker.Checker
+	taskArchive task.Archive
+	taskIndex   fingerprint.Index
+	clusters    cluster.Exporter
+	preprocess  *preprocess.Pipeline
+	exports     []export.Format
+	capture     *capture.Recorder
 }
 
 // Init инициализирует приложение.
@@ -38,18 +50,30 @@ func Init(cfg config.Config) (App, error) {
 
 	// Создание grpc соединения.
 	appLogger.Info("Установление подключения к grpc клиенту")
-	conn, err := grpc.NewClient(cfg.MainServerHost,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithUnaryInterceptor(middleware.NewAuthInterceptor(cfg.MainServerKey)))
-
+	conn, err := newConnection(cfg, appLogger)
 	if err != nil {
-		return nil, fmt.Errorf("grpc: не получается подключиться %s, %v", cfg.MainServerHost, err)
+		return nil, err
 	}
 
 	// Создание grpc клиента.
 	appLogger.Info("Регистрация grpc клиента")
 	grpcClient := orchestrator.NewOrchestratorClient(conn)
 
+	// Сведения о раннере и параметры для его тега.
+	appLogger.Info("Получение сведений о раннере")
+	runner, err := getRunnerInfo(grpcClient)
+	if err != nil {
+		return nil, err
+	}
+	appLogger.Infof("Раннер: id=%d, название=%s, тег=%s", runner.GetID(), runner.GetName(), runner.GetTag())
+	cfg, overrides, err := config.GetRunnerConfig(cfg, runner.GetTag())
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides) != 0 {
+		appLogger.Infof("Переменные среды для тега %s: %v", runner.GetTag(), overrides)
+	}
+
 	// Инициализация хранилища работ студентов.
 	appLogger.Info("Инициализация хранилища работ студентов")
 	storagePath := path.Join(cfg.WorkDir, "storage")
@@ -58,19 +82,72 @@ func Init(cfg config.Config) (App, error) {
 		return nil, err
 	}
 
+	// Индекс отпечатков работ для выбора кандидатов на сравнение.
+	var taskIndex fingerprint.Index
+	if cfg.Candidates != 0 {
+		appLogger.Info("Инициализация индекса отпечатков работ")
+		taskIndex, err = fingerprint.NewIndex(appLogger, path.Join(cfg.WorkDir, "index"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Сервис обработки работ студентов.
 	appLogger.Info("Создание сервиса обработки работ студентов")
-	taskService, err := task.NewService(grpcClient, taskStorage, appLogger, storagePath, cfg.StorageSize)
+	taskService, err := task.NewService(grpcClient, taskStorage, taskIndex, appLogger, storagePath, cfg.StorageSize)
 	if err != nil {
 		return nil, err
 	}
 
+	// Архив работ прошлых событий.
+	var taskArchive task.Archive
+	if cfg.ArchiveDir != "" {
+		appLogger.Infof("Подключение архива работ прошлых событий (%s)", cfg.ArchiveDir)
+		taskArchive, err = task.NewArchive(appLogger, cfg.ArchiveDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Экспорт кластеров схожих работ.
+	var clusters cluster.Exporter
+	if cfg.ClusterLimit != 0 {
+		clusters, err = cluster.NewExporter(path.Join(cfg.WorkDir, "clusters"), cfg.ClusterLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Подготовка исходного кода перед анализом.
+	pipeline := newPipeline(cfg, appLogger)
+
+	// Форматы файлов отчётов проверок.
+	exports := make([]export.Format, 0, len(cfg.ExportFormats))
+	for _, name := range cfg.ExportFormats {
+		format, err := export.ParseFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, format)
+	}
+
 	// Анализатор работ.
-	taskChecker := checker.NewJplagChecker(appLogger, cfg.CheckerPath, path.Join(cfg.WorkDir, "check", "01"), taskService)
+	taskChecker, capabilities, err := newCheckerWithCapabilities(cfg, appLogger)
+	if err != nil {
+		return nil, err
+	}
 
-	appLogger.Info("Приложение успешно инициализировано")
+	// Сообщение серверу о возможностях раннера для выбора совместимых задач.
+	registerCapabilities(grpcClient, appLogger, cfg, capabilities)
+
+	// Запись пакетов воспроизведения задач.
+	var recorder *capture.Recorder
+	if cfg.CaptureDir != "" {
+		appLogger.Infof("Пакеты воспроизведения задач сохраняются в %s", cfg.CaptureDir)
+		recorder = newRecorder(cfg, pipeline != nil)
+	}
 
-	return &appT{
+	a := &appT{
 		grpcConnection: conn,
 		grpcClient:     grpcClient,
 		cfg:            cfg,
@@ -79,7 +156,25 @@ func Init(cfg config.Config) (App, error) {
 		taskStorage: taskStorage,
 		taskService: taskService,
 		taskChecker: taskChecker,
-	}, nil
+		taskArchive: taskArchive,
+		taskIndex:   taskIndex,
+		clusters:    clusters,
+		preprocess:  pipeline,
+		exports:     exports,
+		capture:     recorder,
+	}
+
+	// Удаление устаревших отчётов и пакетов воспроизведения.
+	if cfg.KeepDays == 0 {
+		for _, dir := range a.outputDirs() {
+			appLogger.Warnf("Каталог %s не очищается: срок хранения (keepDays) не задан", dir)
+		}
+	}
+	a.removeOldOutputs()
+
+	appLogger.Info("Приложение успешно инициализировано")
+
+	return a, nil
 }
 
 // Run запускает работу приложения.
@@ -91,6 +186,13 @@ func (a *appT) Run() {
 	// Функция корректного завершения приложения.
 	go a.gracefulShutdown(quit)
 
+	// Уведомления о новых задачах от сервера.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notify := make(chan struct{}, 1)
+	subscribed := &atomic.Bool{}
+	go a.subscribeTasks(ctx, notify, subscribed)
+
 	a.logger.Info("Приложение запущено")
 
 	for isRunning {
@@ -101,6 +203,11 @@ func (a *appT) Run() {
 			isRunning = false
 			scheduler.Stop()
 
+		// Сервер сообщил о новых задачах: проверить сразу.
+		case <-notify:
+			scheduler.Stop()
+			scheduler = time.NewTimer(0)
+
 		// Время проверить наличие новой задачи на сервере.
 		case <-scheduler.C:
 			// Начать процесс выполнения задачи
@@ -112,6 +219,8 @@ func (a *appT) Run() {
 			// Задержка перед следующей задачей.
 			if hasTask {
 				scheduler = time.NewTimer(100 * time.Millisecond)
+			} else if subscribed.Load() {
+				scheduler = time.NewTimer(subscribedPollInterval)
 			} else {
 				scheduler = time.NewTimer(5 * time.Second)
 			}
@@ -120,8 +229,114 @@ func (a *appT) Run() {
 }
 
 func (a *appT) Close() error {
+	if closer, ok := a.taskChecker.(io.Closer); ok {
+		_ = closer.Close()
+	}
 	_ = a.grpcConnection.Close()
 	_ = a.taskStorage.Close()
+	if a.taskIndex != nil {
+		_ = a.taskIndex.Close()
+	}
 	_ = a.logger.Close()
 	return nil
 }
+
+// newPipeline создаёт подготовку исходного кода. Возвращает nil, если подготовка не включена.
+func newPipeline(cfg config.Config, appLogger *logger.Logger) *preprocess.Pipeline {
+	options := pipelineOptions(cfg)
+	if !options.Enabled() && cfg.IgnoreDir == "" {
+		return nil
+	}
+
+	appLogger.Info("Подготовка исходного кода перед анализом включена")
+	return preprocess.NewPipeline(appLogger, path.Join(cfg.WorkDir, "prepared"), options)
+}
+
+// pipelineOptions возвращает параметры подготовки исходного кода.
+func pipelineOptions(cfg config.Config) preprocess.Options {
+	return preprocess.Options{
+		Exclude:       cfg.ExcludeFiles,
+		SkipGenerated: cfg.SkipGenerated,
+		SkipBinary:    cfg.SkipBinary,
+		MaxFileSize:   int64(cfg.MaxFileSize) * 1024,
+		NormalizeEOL:  cfg.NormalizeEOL,
+		Notebooks:     cfg.Notebooks,
+		CSharp:        cfg.CSharpProjects,
+	}
+}
+
+// newRecorder создаёт запись пакетов воспроизведения задач с параметрами анализа из конфигурации.
+// Prepared: выполняется ли подготовка исходного кода.
+func newRecorder(cfg config.Config, prepared bool) *capture.Recorder {
+	analyzer := capture.Checker{
+		Engine:     capture.EngineJplag,
+		Path:       cfg.CheckerPath,
+		Language:   cfg.CheckerLang,
+		OffsetUnit: checker.OffsetUnit(cfg.OffsetUnit),
+		TabWidth:   int(cfg.TabWidth),
+	}
+	if cfg.CheckerPlugin != "" {
+		analyzer.Engine, analyzer.Path = capture.EnginePlugin, cfg.CheckerPlugin
+	}
+
+	var options *preprocess.Options
+	if prepared {
+		o := pipelineOptions(cfg)
+		options = &o
+	}
+
+	return capture.NewRecorder(cfg.CaptureDir, cfg.CaptureWorks, analyzer, options,
+		checker.MergeOptions{MaxGap: cfg.MatchGap, MinSize: cfg.MatchMinSize})
+}
+
+// newChecker создаёт анализатор работ: внешний анализатор, если он указан, иначе Jplag.
+func newChecker(cfg config.Config, appLogger *logger.Logger) (checker.Checker, error) {
+	taskChecker, _, err := newCheckerWithCapabilities(cfg, appLogger)
+	return taskChecker, err
+}
+
+// newCheckerWithCapabilities создаёт анализатор работ и возвращает его возможности.
+// Для внешнего анализатора версия не определяется, язык - из конфигурации.
+func newCheckerWithCapabilities(cfg config.Config, appLogger *logger.Logger) (checker.Checker, checker.Capabilities, error) {
+	offsetUnit, err := checker.ParseOffsetUnit(cfg.OffsetUnit)
+	if err != nil {
+		return nil, checker.Capabilities{}, err
+	}
+
+	options := checker.Options{
+		Language:   cfg.CheckerLang,
+		OffsetUnit: offsetUnit,
+		TabWidth:   int(cfg.TabWidth),
+	}
+
+	if cfg.CheckerPlugin != "" {
+		appLogger.Infof("Анализатор: внешний анализатор %s, язык=%s", cfg.CheckerPlugin, cfg.CheckerLang)
+		capabilities := checker.Capabilities{Engine: "plugin", Languages: []string{cfg.CheckerLang}}
+		return checker.NewPluginChecker(appLogger, cfg.CheckerPlugin, options), capabilities, nil
+	}
+
+	// Проверка совместимости анализатора.
+	appLogger.Info("Проверка анализатора работ")
+	capabilities, err := checker.ProbeJplag(cfg.CheckerPath)
+	if err != nil {
+		return nil, capabilities, fmt.Errorf("анализатор %s: %v", cfg.CheckerPath, err)
+	}
+	if err = capabilities.Check(); err != nil {
+		return nil, capabilities, err
+	}
+	if err = capabilities.CheckLanguage(cfg.CheckerLang); err != nil {
+		appLogger.Warnf("%v: язык будет проверен анализатором при первой задаче", err)
+	}
+	if capabilities.Version == (checker.Version{}) {
+		appLogger.Warnf("Не удалось определить версию анализатора %s", cfg.CheckerPath)
+	}
+	if len(capabilities.Languages) == 0 {
+		appLogger.Warnf("Не удалось определить языки анализатора %s", cfg.CheckerPath)
+	}
+	appLogger.Infof("Анализатор: %s %s, язык=%s, доступные языки=%v",
+		capabilities.Engine, capabilities.Version, cfg.CheckerLang, capabilities.Languages)
+
+	return checker.NewJplagChecker(appLogger, cfg.CheckerPath,
+		path.Join(cfg.WorkDir, "check", "01"), cfg.CheckerWorker,
+		time.Duration(cfg.WorkerTimeout)*time.Second, options), capabilities, nil
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/config"
+	"CodeBorrowing/internal/export"
+	"CodeBorrowing/internal/logger"
+	"io"
+	"os"
+	"path"
+)
+
+// CheckOptions - параметры автономной проверки.
+type CheckOptions struct {
+	New     []string      // Каталоги с новыми работами (работы в подкаталогах).
+	Old     []string      // Каталоги со старыми работами (работы в подкаталогах).
+	EventID uint64        // Событие для правил игнорирования файлов (0 - только общие правила).
+	Tags    []string      // Теги для правил игнорирования файлов.
+	Output  io.Writer     // Поток вывода отчёта.
+	Format  export.Format // Формат отчёта.
+	HTMLDir string        // Каталог для отчётов HTML по парам работ (пусто - не сохраняются).
+}
+
+// Check выполняет автономную проверку каталогов с работами без подключения к серверу.
+// Логи выводятся в stderr и в каталог логов cfg.WorkDir, отчёт в формате options.Format - в options.Output.
+func Check(cfg config.Config, options CheckOptions) error {
+	appLogger := logger.NewLoggerWithConsole(path.Join(cfg.WorkDir, "logs"), os.Stderr)
+	defer appLogger.Close()
+
+	taskChecker, err := newChecker(cfg, appLogger)
+	if err != nil {
+		return err
+	}
+	if closer, ok := taskChecker.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	a := &appT{
+		cfg:         cfg,
+		logger:      appLogger,
+		taskChecker: taskChecker,
+		preprocess:  newPipeline(cfg, appLogger),
+	}
+
+	// Анализ работ.
+	ignore, err := a.loadIgnore(options.EventID, options.Tags)
+	if err != nil {
+		return err
+	}
+	result, err := a.runChecker(ignore, options.New, options.Old, nil)
+	if err != nil {
+		return err
+	}
+	checker.MergeMatches(result, checker.MergeOptions{MaxGap: cfg.MatchGap, MinSize: cfg.MatchMinSize})
+	appLogger.Infof("Работы проанализированы (пар работ: %d)", len(result))
+
+	// Отчёты HTML по парам работ.
+	if options.HTMLDir != "" {
+		if err = export.SaveHTML(options.HTMLDir, result); err != nil {
+			return err
+		}
+		appLogger.Infof("Отчёты HTML сохранены в %s", options.HTMLDir)
+	}
+
+	// Вывод отчёта.
+	var info checker.RunInfo
+	if provider, ok := taskChecker.(checker.RunInfoProvider); ok {
+		info = provider.LastRunInfo()
+	}
+
+	return export.Write(options.Output, options.Format, info, result)
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"CodeBorrowing/internal/config"
+	"CodeBorrowing/internal/logger"
+	"CodeBorrowing/internal/middleware"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Время ожидания проверочного TLS соединения с главным сервером.
+const handshakeTimeout = 10 * time.Second
+
+// Время ожидания ответа сервера авторизации.
+const authTimeout = 30 * time.Second
+
+// newConnection создаёт подключение grpc к главному серверу.
+// При включённом TLS сначала устанавливается проверочное соединение: ошибки сертификатов
+// обнаруживаются при запуске, а не при первом вызове.
+func newConnection(cfg config.Config, appLogger *logger.Logger) (*grpc.ClientConn, error) {
+	var tlsConfig *tls.Config
+	creds := insecure.NewCredentials()
+	if cfg.MainServerTLS {
+		var err error
+		if tlsConfig, err = newTLSConfig(cfg); err != nil {
+			return nil, err
+		}
+
+		appLogger.Infof("Проверка TLS соединения с %s", cfg.MainServerHost)
+		if err = checkTLS(cfg.MainServerHost, tlsConfig); err != nil {
+			return nil, fmt.Errorf("grpc: не удалось установить TLS соединение с %s: %v", cfg.MainServerHost, err)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	} else {
+		appLogger.Warnf("Подключение к %s без шифрования: ключ и работы передаются открытым текстом", cfg.MainServerHost)
+	}
+
+	options, err := newAuthOptions(cfg, tlsConfig, appLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(cfg.MainServerHost, append(options, grpc.WithTransportCredentials(creds))...)
+	if err != nil {
+		return nil, fmt.Errorf("grpc: не получается подключиться %s, %v", cfg.MainServerHost, err)
+	}
+
+	return conn, nil
+}
+
+// newAuthOptions создаёт авторизацию вызовов и потоков: краткосрочные токены сервера авторизации,
+// если он указан, иначе ключ идентификации в каждом вызове. Первый токен получается сразу,
+// чтобы ошибки авторизации обнаруживались при запуске.
+// TlsConfig: параметры TLS главного сервера (nil - без TLS), используются и для сервера авторизации.
+func newAuthOptions(cfg config.Config, tlsConfig *tls.Config, appLogger *logger.Logger) ([]grpc.DialOption, error) {
+	if cfg.AuthURL == "" {
+		return []grpc.DialOption{
+			grpc.WithUnaryInterceptor(middleware.NewAuthInterceptor(cfg.MainServerKey)),
+			grpc.WithStreamInterceptor(middleware.NewAuthStreamInterceptor(cfg.MainServerKey)),
+		}, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		// Имя главного сервера к серверу авторизации не относится.
+		transport.TLSClientConfig = tlsConfig.Clone()
+		transport.TLSClientConfig.ServerName = ""
+	}
+	tokens := middleware.NewTokens(cfg.AuthURL, cfg.MainServerKey, &http.Client{Transport: transport, Timeout: authTimeout})
+
+	appLogger.Infof("Получение токена авторизации (%s)", cfg.AuthURL)
+	ctx, cancel := context.WithTimeout(context.Background(), authTimeout)
+	defer cancel()
+	if _, err := tokens.Token(ctx); err != nil {
+		return nil, err
+	}
+
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(middleware.NewTokenAuthInterceptor(tokens)),
+		grpc.WithStreamInterceptor(middleware.NewTokenAuthStreamInterceptor(tokens)),
+	}, nil
+}
+
+// newTLSConfig создаёт параметры TLS: сертификаты центров сертификации (по умолчанию системные),
+// сертификат раннера для взаимной аутентификации и имя сервера для проверки сертификата.
+func newTLSConfig(cfg config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: cfg.MainServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.MainServerCA != "" {
+		content, err := os.ReadFile(cfg.MainServerCA)
+		if err != nil {
+			return nil, fmt.Errorf("сертификаты центров сертификации: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("сертификаты центров сертификации: в файле %s нет сертификатов PEM", cfg.MainServerCA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.RunnerCert != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.RunnerCert, cfg.RunnerKey)
+		if err != nil {
+			return nil, fmt.Errorf("сертификат раннера: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// checkTLS устанавливает проверочное TLS соединение с сервером addr.
+func checkTLS(addr string, tlsConfig *tls.Config) error {
+	probeConfig := tlsConfig.Clone()
+	probeConfig.NextProtos = []string{"h2"}
+
+	dialer := &net.Dialer{Timeout: handshakeTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, probeConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// В TLS 1.3 сервер проверяет сертификат раннера после завершения рукопожатия на стороне раннера
+	// и сообщает об ошибке следующим сообщением. Ожидание ответа сервера ограничено:
+	// сервер grpc может ничего не отправлять до получения запроса.
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+
+	var netErr net.Error
+	if err == nil || errors.As(err, &netErr) && netErr.Timeout() {
+		return nil
+	}
+	return err
+}
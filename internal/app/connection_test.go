@@ -0,0 +1,232 @@
+package app
+
+import (
+	"CodeBorrowing/internal/orchestratortest"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// testCA - центр сертификации для тестов.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue выпускает сертификат для имени dnsName и возвращает его и закрытый ключ в формате PEM.
+func (ca *testCA) issue(t *testing.T, dnsName string, usage x509.ExtKeyUsage) (certPEM []byte, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile записывает содержимое в файл каталога теста и возвращает путь к нему.
+func writeFile(t *testing.T, dir string, name string, content []byte) string {
+	t.Helper()
+
+	filePath := path.Join(dir, name)
+	if err := os.WriteFile(filePath, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func TestInitTLS(t *testing.T) {
+	dir := t.TempDir()
+	serverCA, runnerCA, otherCA := newTestCA(t, "server ca"), newTestCA(t, "runner ca"), newTestCA(t, "other ca")
+
+	// Сертификат сервера выдан на имя orchestrator.test, а не на адрес 127.0.0.1.
+	serverCert, serverKey := serverCA.issue(t, "orchestrator.test", x509.ExtKeyUsageServerAuth)
+	certificate, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runnerPool := x509.NewCertPool()
+	runnerPool.AddCert(runnerCA.cert)
+
+	server := orchestratortest.NewServer()
+	server.SetKey("secret")
+	server.SetTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    runnerPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	if err = server.Start(""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	runnerCert, runnerKey := runnerCA.issue(t, "runner", x509.ExtKeyUsageClientAuth)
+	serverCAFile := writeFile(t, dir, "server-ca.pem", serverCA.pem)
+	otherCAFile := writeFile(t, dir, "other-ca.pem", otherCA.pem)
+	runnerCertFile := writeFile(t, dir, "runner.pem", runnerCert)
+	runnerKeyFile := writeFile(t, dir, "runner-key.pem", runnerKey)
+
+	tests := []struct {
+		name       string
+		ca         string
+		serverName string
+		cert       string
+		key        string
+		ok         bool
+	}{
+		{name: "mtls", ca: serverCAFile, serverName: "orchestrator.test", cert: runnerCertFile, key: runnerKeyFile, ok: true},
+		{name: "unknown ca", ca: otherCAFile, serverName: "orchestrator.test", cert: runnerCertFile, key: runnerKeyFile},
+		{name: "server name", ca: serverCAFile, cert: runnerCertFile, key: runnerKeyFile},
+		{name: "no runner cert", ca: serverCAFile, serverName: "orchestrator.test"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := testConfig(t, server)
+			cfg.MainServerTLS = true
+			cfg.MainServerCA = test.ca
+			cfg.MainServerName = test.serverName
+			cfg.RunnerCert = test.cert
+			cfg.RunnerKey = test.key
+
+			application, err := Init(cfg)
+			if !test.ok {
+				if err == nil {
+					_ = application.Close()
+					t.Fatal("Init: ожидалась ошибка TLS соединения")
+				}
+				t.Log(err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = application.Close() })
+
+			// Вызовы проходят по защищённому соединению.
+			if application.(*appT).process() {
+				t.Error("process: получены задачи, хотя задач нет")
+			}
+			if len(server.Calls()) == 0 {
+				t.Error("сервер не получил вызовов")
+			}
+		})
+	}
+}
+
+func TestInitTokens(t *testing.T) {
+	server := orchestratortest.NewServer()
+	server.SetKey("secret")
+	server.EnableTokens(2 * time.Second)
+	if err := server.Start(""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	// Неверный ключ: токен не выдаётся, запуск завершается ошибкой.
+	cfg := testConfig(t, server)
+	cfg.AuthURL = server.AuthURL()
+	cfg.MainServerKey = "wrong"
+	if application, err := Init(cfg); err == nil {
+		_ = application.Close()
+		t.Fatal("Init: ожидалась ошибка авторизации")
+	}
+
+	cfg.MainServerKey = "secret"
+	application, err := Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = application.Close() })
+	a := application.(*appT)
+
+	// process выполняет задачу с единственной работой: задача закрывается без анализа.
+	process := func(id uint64) {
+		t.Helper()
+		if err := server.AddWork(id, id, map[string]string{"Main.cs": "class A { }"}); err != nil {
+			t.Fatal(err)
+		}
+		server.AddTask(id, id, id, "")
+		a.process()
+		if status := server.TaskStatus(id); status != orchestratortest.StatusDone {
+			t.Errorf("состояние задачи %d: %s", id, status)
+		}
+	}
+
+	// Первый токен получен при запуске и используется в вызовах.
+	process(1)
+	if issued := server.IssuedTokens(); issued != 1 {
+		t.Errorf("выдано токенов: %d, ожидался 1", issued)
+	}
+
+	// Отозванный токен: вызов повторяется с новым токеном.
+	server.RevokeTokens()
+	process(2)
+	if issued := server.IssuedTokens(); issued != 2 {
+		t.Errorf("выдано токенов после отзыва: %d, ожидалось 2", issued)
+	}
+
+	// Токен обновляется заранее, до истечения срока действия (2 с).
+	time.Sleep(1100 * time.Millisecond)
+	process(3)
+	if issued := server.IssuedTokens(); issued != 3 {
+		t.Errorf("выдано токенов после половины срока действия: %d, ожидалось 3", issued)
+	}
+}
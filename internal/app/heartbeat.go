@@ -0,0 +1,86 @@
+package app
+
+import (
+	"CodeBorrowing/internal/task"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Интервал продления аренды задач, если сервер не сообщил время аренды.
+const defaultHeartbeatInterval = 30 * time.Second
+
+// heartbeat - продление аренды выполняемых задач.
+type heartbeat struct {
+	quit chan struct{}
+	done chan struct{}
+
+	mu   sync.Mutex
+	lost []uint64 // задачи, аренда которых истекла.
+}
+
+// startHeartbeat продлевает аренду задач, пока они выполняются: сразу и затем трижды за время аренды,
+// назначенное сервером. Если сервер не поддерживает аренду задач, продление прекращается.
+// Продление останавливается вызовом Stop перед закрытием задач.
+func (a *appT) startHeartbeat(tasksID []uint64) *heartbeat {
+	h := &heartbeat{
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(h.done)
+
+		ids := slices.Clone(tasksID)
+		for {
+			interval := defaultHeartbeatInterval
+
+			lease, lost, err := a.taskService.ExtendTaskLease(ids)
+			if errors.Is(err, task.ErrLeaseUnsupported) {
+				a.logger.Debug("Сервер не поддерживает аренду задач")
+				return
+			}
+			if err != nil {
+				a.logger.Warnf("Не удалось продлить аренду задач %v: %v", ids, err)
+			} else {
+				if len(lost) != 0 {
+					a.logger.Warnf("Аренда задач %v истекла: задачи могут быть переданы другому раннеру", lost)
+					h.mu.Lock()
+					h.lost = append(h.lost, lost...)
+					h.mu.Unlock()
+
+					ids = slices.DeleteFunc(ids, func(id uint64) bool { return slices.Contains(lost, id) })
+					if len(ids) == 0 {
+						return
+					}
+				}
+				if lease != 0 {
+					interval = lease / 3
+				}
+			}
+
+			select {
+			case <-h.quit:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+
+	return h
+}
+
+// Lost возвращает задачи, аренда которых истекла.
+func (h *heartbeat) Lost() []uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.lost)
+}
+
+// Stop останавливает продление аренды и возвращает задачи, аренда которых истекла.
+func (h *heartbeat) Stop() []uint64 {
+	close(h.quit)
+	<-h.done
+	return h.Lost()
+}
@@ -1,11 +1,29 @@
 package app
 
 import (
+	"CodeBorrowing/internal/capture"
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/export"
+	"CodeBorrowing/internal/preprocess"
 	"CodeBorrowing/internal/task"
+	"CodeBorrowing/internal/utils"
 	"CodeBorrowing/services/orchestrator"
 	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"time"
 )
 
+// Единицы измерения позиций фрагментов в отчёте для сервера.
+var offsetUnits = map[checker.OffsetUnit]orchestrator.OffsetUnit{
+	checker.UnitRunes: orchestrator.OffsetUnit_OFFSET_UNIT_RUNES,
+	checker.UnitBytes: orchestrator.OffsetUnit_OFFSET_UNIT_BYTES,
+	checker.UnitUTF16: orchestrator.OffsetUnit_OFFSET_UNIT_UTF16,
+}
+
 // Process запускает главный процесс приложения.
 // Возвращает true, если задача была получена, иначе false.
 func (a *appT) process() bool {
@@ -33,92 +51,171 @@ func (a *appT) process() bool {
 
 	a.logger.Infof("Получены новые задачи (eventId=%d, worksId=%v). Загрузка работ", eventID, newWorksIDArr)
 
+	// Продление аренды задач, пока они выполняются.
+	lease := a.startHeartbeat(tasksID)
+
 	// Получение id всех работы из event.
 	works, err := a.taskService.GetEventWorks(eventID)
 	if err != nil {
 		a.logger.Error(err)
 
 		// Отправка серверу сигнала о том, что выполнение задачи завершено с ошибкой.
-		if err = a.taskService.CloseTaskWithError(tasksID); err != nil {
-			a.logger.Error(err)
-		}
+		a.closeTasks(lease, tasksID, true)
 
 		return true
 	}
 	a.logger.Infof("Работы Загружены (count=%d). Начинаем анализ.", len(works))
 
+	newWorks := make([]string, 0, len(tasks))            // путь к каталогам с новыми работами.
+	newWorksEntry := make(map[uint64]string, len(tasks)) // пути к каталогам с новыми работами по их id.
+	oldWorksEntry := make(map[uint64]string, len(works)) // пути к каталогам с остальными работами по их id.
+
+	// Цикл определяет новые и остальные работы.
+	eventWorksID := make(map[uint64]any, len(works)) // множество id работ event-а.
+	for _, work := range works {
+		eventWorksID[work.WorkID] = nil
+		_, ok := newWorksID[work.WorkID]
+		if ok {
+			newWorks = append(newWorks, work.Path)
+			newWorksEntry[work.WorkID] = work.Path
+		} else {
+			oldWorksEntry[work.WorkID] = work.Path
+		}
+	}
+
+	// Добавление работ прошлых событий из архива.
+	archiveWorks := a.getArchiveWorks(eventID, eventWorksID)
+	for _, work := range archiveWorks {
+		oldWorksEntry[work.WorkID] = work.Path
+	}
+
+	// Выбор работ-кандидатов для сравнения по индексу отпечатков.
+	if a.taskIndex != nil && len(newWorksEntry) != 0 && uint64(len(oldWorksEntry)) > a.cfg.Candidates {
+		oldWorksEntry = a.selectCandidates(newWorksEntry, oldWorksEntry)
+	}
+
+	oldWorks := make([]string, 0, len(oldWorksEntry)) // путь к каталогам с остальными работами.
+	for _, workPath := range oldWorksEntry {
+		oldWorks = append(oldWorks, workPath)
+	}
+
 	// Если работу не с чем сравнивать.
-	if len(works) <= 1 {
+	if len(newWorks)+len(oldWorks) <= 1 {
 		a.logger.Info("Единственную работу не с чем сравнивать")
 
 		// Отправка серверу сигнала о том, что выполнение задачи завершено.
-		if err = a.taskService.CloseTask(tasksID); err != nil {
-			a.logger.Error(err)
-		}
+		a.closeTasks(lease, tasksID, false)
 
 		return true
 	}
 
-	newWorks := make([]string, 0, len(tasks)) // путь к каталогам с новыми работами.
-	oldWorksCount := len(works) - len(tasks)
-	if oldWorksCount < 0 {
-		oldWorksCount = 0
-	}
-	oldWorks := make([]string, 0, oldWorksCount) // путь к каталогам с остальными работами.
+	// Запуск анализа работ с правилами игнорирования файлов события.
+	var result []*checker.ReportItem
+	ignore, err := a.loadIgnore(eventID, taskTags(tasks))
+	if err == nil {
+		// Пакет воспроизведения задачи.
+		var bundle *capture.Capture
+		if a.capture != nil {
+			bundle = a.beginCapture(eventID, tasks, ignore, newWorks, oldWorks, archiveWorks)
+		}
 
-	// Цикл определяет новые и остальные работы.
-	for _, work := range works {
-		_, ok := newWorksID[work.WorkID]
-		if ok {
-			newWorks = append(newWorks, work.Path)
-		} else {
-			oldWorks = append(oldWorks, work.Path)
+		result, err = a.runChecker(ignore, newWorks, oldWorks, bundle)
+		if bundle != nil {
+			a.finishCapture(bundle, err)
 		}
 	}
-
-	// Запуск анализа работ.
-	result, err := a.taskChecker.Run(newWorks, oldWorks)
 	if err != nil {
 		a.logger.Error(err)
 
 		// Отправка серверу сигнала о том, что выполнение задачи завершено с ошибкой.
-		if err = a.taskService.CloseTaskWithError(tasksID); err != nil {
-			a.logger.Error(err)
-		}
+		a.closeTasks(lease, tasksID, true)
 		return true
 	}
 	a.logger.Info("Работы успешно проанализированы. Отправка отчёта")
 
+	// Сведения о запуске анализа.
+	if provider, ok := a.taskChecker.(checker.RunInfoProvider); ok {
+		info := provider.LastRunInfo()
+		a.logger.Debugf("Анализ: версия=%d.%d.%d, язык=%s, сравнений=%d, время=%dмс",
+			info.CheckerVersion.Major, info.CheckerVersion.Minor, info.CheckerVersion.Patch,
+			info.Language, info.TotalComparisons, info.ExecutionTime)
+		for _, failed := range info.FailedSubmissions {
+			a.logger.Warnf("Анализатор не смог обработать работу %s (%s)", failed.Name, failed.State)
+		}
+	}
+
+	// Объединение пересекающихся и близких фрагментов совпадений.
+	checker.MergeMatches(result, checker.MergeOptions{MaxGap: a.cfg.MatchGap, MinSize: a.cfg.MatchMinSize})
+
+	// Новые работы задач, аренда которых не истекла.
+	lost := lease.Lost()
+	leasedWorksID := make(map[uint64]any, len(tasks))
+	for _, t := range tasks {
+		if !slices.Contains(lost, t.ID) {
+			leasedWorksID[t.WorkID] = nil
+		}
+	}
+
 	// Обработка результата.
 	for _, res := range result {
-		// Формирование отчёта.
-		report := &orchestrator.SendCrossCheckReportRequest{
-			FirstWorkID:  res.Work1ID,
-			SecondWorkID: res.Work2ID,
-			Match:        make([]*orchestrator.SendCrossCheckReportMatches, len(res.Matches)),
-		}
-
-		// Обработка совпадений.
-		for i, m := range res.Matches {
-			report.Match[i] = &orchestrator.SendCrossCheckReportMatches{
-				FirstWorkPath:   m.Work1File,
-				FirstWorkStart:  m.Work1Start,
-				FirstWorkSize:   m.Work1Size,
-				SecondWorkPath:  m.Work2File,
-				SecondWorkStart: m.Work2Start,
-				SecondWorkSize:  m.Work2Size,
-			}
+		// Работы, не сопоставленные с id, не отправляются.
+		if res.Work1ID == 0 || res.Work2ID == 0 {
+			a.logger.Errorf("Не удалось определить id работ %s и %s", res.Work1Name, res.Work2Name)
+			continue
+		}
+
+		// Пары работ только задач с истёкшей арендой отправит раннер, получивший эти задачи.
+		_, leased1 := leasedWorksID[res.Work1ID]
+		_, leased2 := leasedWorksID[res.Work2ID]
+		if !leased1 && !leased2 {
+			continue
+		}
+
+		// Определение событий, к которым относятся работы.
+		res.Work1EventID, res.Work2EventID = eventID, eventID
+		if work, ok := archiveWorks[res.Work1ID]; ok {
+			res.Work1EventID = work.EventID
+		}
+		if work, ok := archiveWorks[res.Work2ID]; ok {
+			res.Work2EventID = work.EventID
+		}
+		if res.Work1EventID != eventID || res.Work2EventID != eventID {
+			a.logger.Infof("Совпадение с работой из архива (work1Id=%d, event1Id=%d, work2Id=%d, event2Id=%d)",
+				res.Work1ID, res.Work1EventID, res.Work2ID, res.Work2EventID)
 		}
 
 		// Отправка отчета.
-		if err = a.taskService.SendReport(report); err != nil {
+		if err = a.taskService.SendReport(newReportRequest(res)); err != nil {
 			a.logger.Error(err)
 		}
 	}
 
 	// Отправка серверу сигнала о том, что выполнение задачи завершено.
-	if err = a.taskService.CloseTask(tasksID); err != nil {
-		a.logger.Error(err)
+	a.closeTasks(lease, tasksID, false)
+
+	// Сохранение отчётов HTML по парам работ.
+	if a.cfg.HTMLReports {
+		dir := path.Join(a.cfg.WorkDir, "reports", strconv.FormatUint(eventID, 10))
+		if err = export.SaveHTML(dir, result); err != nil {
+			a.logger.Error(err)
+		}
+	}
+
+	// Сохранение файлов отчёта проверки.
+	if len(a.exports) != 0 {
+		a.saveExports(eventID, tasksID[0], result)
+	}
+
+	// Обновление кластеров схожих работ события.
+	if a.clusters != nil {
+		a.updateClusters(eventID, result)
+	}
+
+	// Сохранение работ события в архив.
+	if a.taskArchive != nil && a.cfg.ArchiveSave {
+		if err = a.taskArchive.SaveWorks(eventID, works); err != nil {
+			a.logger.Error(err)
+		}
 	}
 
 	// Проверка лимита занятого места на диске.
@@ -126,5 +223,307 @@ func (a *appT) process() bool {
 		a.logger.Error(err)
 	}
 
+	// Удаление устаревших отчётов и пакетов воспроизведения.
+	a.removeOldOutputs()
+
 	return true
 }
+
+// closeTasks останавливает продление аренды задач и сообщает серверу о завершении задач
+// (failed - с ошибкой). Задачи, аренда которых истекла, не закрываются: сервер мог передать их другому раннеру.
+func (a *appT) closeTasks(lease *heartbeat, tasksID []uint64, failed bool) {
+	lost := lease.Stop()
+	tasksID = slices.DeleteFunc(slices.Clone(tasksID), func(id uint64) bool { return slices.Contains(lost, id) })
+	if len(tasksID) == 0 {
+		a.logger.Warnf("Задачи %v не закрыты: аренда задач истекла", lost)
+		return
+	}
+
+	var err error
+	if failed {
+		err = a.taskService.CloseTaskWithError(tasksID)
+	} else {
+		err = a.taskService.CloseTask(tasksID)
+	}
+	if err != nil {
+		a.logger.Error(err)
+	}
+}
+
+// newReportRequest формирует отчёт о паре работ для сервера.
+func newReportRequest(res *checker.ReportItem) *orchestrator.SendCrossCheckReportRequest {
+	report := &orchestrator.SendCrossCheckReportRequest{
+		FirstWorkID:       res.Work1ID,
+		SecondWorkID:      res.Work2ID,
+		Match:             make([]*orchestrator.SendCrossCheckReportMatches, len(res.Matches)),
+		FirstWorkEventID:  res.Work1EventID,
+		SecondWorkEventID: res.Work2EventID,
+		OffsetUnit:        offsetUnits[res.OffsetUnit],
+	}
+
+	// Обработка совпадений.
+	for i, m := range res.Matches {
+		report.Match[i] = &orchestrator.SendCrossCheckReportMatches{
+			FirstWorkPath:   m.Work1File,
+			FirstWorkStart:  m.Work1Start,
+			FirstWorkSize:   m.Work1Size,
+			SecondWorkPath:  m.Work2File,
+			SecondWorkStart: m.Work2Start,
+			SecondWorkSize:  m.Work2Size,
+			FirstWorkCell:   m.Work1Cell,
+			SecondWorkCell:  m.Work2Cell,
+		}
+	}
+
+	return report
+}
+
+// runChecker запускает анализ работ. Если включена подготовка исходного кода,
+// анализируются подготовленные работы, а результат переводится к исходным работам.
+// Ignore: правила игнорирования файлов (nil - без правил).
+// Bundle: пакет воспроизведения для исходного результата анализатора (nil - не сохраняется).
+func (a *appT) runChecker(ignore *preprocess.Ignore, newWorks []string, oldWorks []string, bundle *capture.Capture) ([]*checker.ReportItem, error) {
+	run := a.taskChecker.Run
+	if capturer, ok := a.taskChecker.(checker.Capturer); ok && bundle != nil {
+		run = func(newWorks []string, oldWorks []string) ([]*checker.ReportItem, error) {
+			return capturer.RunCapture(newWorks, oldWorks, bundle.Output())
+		}
+	}
+
+	return a.analyze(ignore, newWorks, oldWorks, run)
+}
+
+// analyze выполняет анализ работ функцией run с подготовкой исходного кода, если она включена.
+func (a *appT) analyze(ignore *preprocess.Ignore, newWorks []string, oldWorks []string,
+	run func(newWorks []string, oldWorks []string) ([]*checker.ReportItem, error)) ([]*checker.ReportItem, error) {
+	if a.preprocess == nil {
+		return run(newWorks, oldWorks)
+	}
+
+	prepared, err := a.preprocess.Prepare(newWorks, oldWorks, ignore)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := prepared.Remove(); err != nil {
+			a.logger.Error(err)
+		}
+	}()
+
+	result, err := run(prepared.New, prepared.Old)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = prepared.Restore(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// beginCapture создаёт пакет воспроизведения задачи. Возвращает nil, если пакет создать не удалось.
+// NewWorks, oldWorks: каталоги работ в порядке передачи анализатору.
+func (a *appT) beginCapture(eventID uint64, tasks []*orchestrator.Task, ignore *preprocess.Ignore,
+	newWorks []string, oldWorks []string, archiveWorks map[uint64]task.ArchiveEntry) *capture.Capture {
+	bundleTasks := make([]capture.Task, len(tasks))
+	for i, t := range tasks {
+		bundleTasks[i] = capture.Task{ID: t.ID, WorkID: t.WorkID, Tag: t.Tag}
+	}
+
+	// Id работы - название её единственного подкаталога, как и при сопоставлении результата анализа.
+	works := func(roots []string) []capture.Work {
+		result := make([]capture.Work, len(roots))
+		for i, root := range roots {
+			result[i] = capture.Work{EventID: eventID, Root: root}
+			if entries, err := os.ReadDir(root); err == nil && len(entries) == 1 {
+				result[i].WorkID, _ = strconv.ParseUint(entries[0].Name(), 10, 64)
+			}
+			if work, ok := archiveWorks[result[i].WorkID]; ok {
+				result[i].EventID = work.EventID
+			}
+		}
+		return result
+	}
+
+	bundle, err := a.capture.Begin(eventID, bundleTasks, works(newWorks), works(oldWorks), ignore)
+	if err != nil {
+		a.logger.Errorf("Не удалось создать пакет воспроизведения задачи: %v", err)
+		return nil
+	}
+	return bundle
+}
+
+// finishCapture сохраняет описание пакета воспроизведения задачи.
+// CheckErr: ошибка анализа (nil - анализ выполнен).
+func (a *appT) finishCapture(bundle *capture.Capture, checkErr error) {
+	var info checker.RunInfo
+	if provider, ok := a.taskChecker.(checker.RunInfoProvider); ok && checkErr == nil {
+		info = provider.LastRunInfo()
+	}
+
+	if err := bundle.Finish(info, checkErr); err != nil {
+		a.logger.Errorf("Не удалось сохранить пакет воспроизведения задачи: %v", err)
+		return
+	}
+	a.logger.Infof("Пакет воспроизведения задачи сохранён в %s", bundle.Dir())
+}
+
+// saveExports сохраняет отчёт проверки в файлы <workdir>/exports/<eventID>/<время>_<id первой задачи>.<формат>.
+func (a *appT) saveExports(eventID uint64, taskID uint64, result []*checker.ReportItem) {
+	var info checker.RunInfo
+	if provider, ok := a.taskChecker.(checker.RunInfoProvider); ok {
+		info = provider.LastRunInfo()
+	}
+
+	dir := path.Join(a.cfg.WorkDir, "exports", strconv.FormatUint(eventID, 10))
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		a.logger.Error(err)
+		return
+	}
+
+	name := fmt.Sprintf("%s_%d", time.Now().UTC().Format("20060102_150405"), taskID)
+	for _, format := range a.exports {
+		f, err := os.Create(path.Join(dir, name+format.Ext()))
+		if err != nil {
+			a.logger.Error(err)
+			continue
+		}
+
+		if err = export.Write(f, format, info, result); err != nil {
+			a.logger.Error(err)
+		}
+		_ = f.Close()
+	}
+}
+
+// outputDirs возвращает включённые каталоги отчётов HTML, файлов отчётов и пакетов воспроизведения.
+// Каталоги содержат подкаталоги событий.
+func (a *appT) outputDirs() []string {
+	var result []string
+	if a.cfg.HTMLReports {
+		result = append(result, path.Join(a.cfg.WorkDir, "reports"))
+	}
+	if len(a.exports) != 0 {
+		result = append(result, path.Join(a.cfg.WorkDir, "exports"))
+	}
+	if a.capture != nil {
+		result = append(result, a.cfg.CaptureDir)
+	}
+	return result
+}
+
+// removeOldOutputs удаляет отчёты и пакеты воспроизведения, которые хранятся дольше cfg.KeepDays.
+func (a *appT) removeOldOutputs() {
+	if a.cfg.KeepDays == 0 {
+		return
+	}
+
+	limit := time.Duration(a.cfg.KeepDays) * 24 * time.Hour
+	for _, dir := range a.outputDirs() {
+		if err := utils.RemoveOldEntries(dir, limit); err != nil {
+			a.logger.Error(err)
+		}
+	}
+}
+
+// loadIgnore читает правила игнорирования файлов события и тегов. Возвращает nil, если правила не настроены.
+func (a *appT) loadIgnore(eventID uint64, tags []string) (*preprocess.Ignore, error) {
+	if a.cfg.IgnoreDir == "" {
+		return nil, nil
+	}
+	return preprocess.LoadIgnore(a.cfg.IgnoreDir, eventID, tags)
+}
+
+// taskTags возвращает различные теги задач.
+func taskTags(tasks []*orchestrator.Task) []string {
+	tags := make([]string, 0, 1)
+	for _, t := range tasks {
+		if !slices.Contains(tags, t.Tag) {
+			tags = append(tags, t.Tag)
+		}
+	}
+	return tags
+}
+
+// getArchiveWorks получает работы прошлых событий из архива.
+// Работы, которые входят в текущий event, пропускаются.
+// Возвращает работы архива по их id.
+func (a *appT) getArchiveWorks(eventID uint64, eventWorksID map[uint64]any) map[uint64]task.ArchiveEntry {
+	result := make(map[uint64]task.ArchiveEntry)
+	if a.taskArchive == nil {
+		return result
+	}
+
+	works, err := a.taskArchive.GetWorks(eventID)
+	if err != nil {
+		a.logger.Error(err)
+		return result
+	}
+
+	for _, work := range works {
+		if _, ok := eventWorksID[work.WorkID]; ok {
+			continue
+		}
+		result[work.WorkID] = work
+	}
+
+	if len(result) != 0 {
+		a.logger.Infof("Из архива добавлены работы прошлых событий (count=%d)", len(result))
+	}
+
+	return result
+}
+
+// selectCandidates выбирает из старых работ кандидатов для сравнения с новыми работами.
+// Для каждой новой работы выбирается не более cfg.Candidates работ с наибольшим числом общих отпечатков.
+func (a *appT) selectCandidates(newWorks map[uint64]string, oldWorks map[uint64]string) map[uint64]string {
+	// Индексация работ, которых ещё нет в индексе.
+	allowed := make(map[uint64]any, len(oldWorks))
+	for id, workPath := range oldWorks {
+		if !a.taskIndex.Has(id) {
+			if err := a.taskIndex.Add(id, workPath); err != nil {
+				a.logger.Error(err)
+			}
+		}
+		allowed[id] = nil
+	}
+
+	result := make(map[uint64]string)
+	for id, workPath := range newWorks {
+		if !a.taskIndex.Has(id) {
+			if err := a.taskIndex.Add(id, workPath); err != nil {
+				a.logger.Error(err)
+				return oldWorks
+			}
+		}
+
+		// Поиск кандидатов.
+		candidates, err := a.taskIndex.Candidates(id, allowed, int(a.cfg.Candidates))
+		if err != nil {
+			a.logger.Error(err)
+			return oldWorks
+		}
+
+		for _, candidate := range candidates {
+			result[candidate] = oldWorks[candidate]
+		}
+	}
+
+	a.logger.Infof("Выбраны работы-кандидаты для сравнения (count=%d из %d)", len(result), len(oldWorks))
+	return result
+}
+
+// updateClusters обновляет кластеры схожих работ события.
+func (a *appT) updateClusters(eventID uint64, result []*checker.ReportItem) {
+	report, err := a.clusters.Update(eventID, result)
+	if err != nil {
+		a.logger.Error(err)
+		return
+	}
+
+	for _, c := range report.Clusters {
+		a.logger.Infof("Кластер схожих работ (eventId=%d, worksId=%v, avg=%.2f, max=%.2f)",
+			eventID, c.Members, c.AvgSimilarity, c.MaxSimilarity)
+	}
+}
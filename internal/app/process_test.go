@@ -0,0 +1,362 @@
+package app
+
+import (
+	"CodeBorrowing/internal/capture"
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/config"
+	"CodeBorrowing/internal/export"
+	"CodeBorrowing/internal/orchestratortest"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// stubChecker - анализатор для тестов: сравнивает каждую новую работу со всеми следующими работами
+// и сообщает одно совпадение в файле Main.cs.
+type stubChecker struct {
+	err   error
+	delay time.Duration     // длительность анализа.
+	files map[uint64]string // содержимое Main.cs переданных работ по id.
+}
+
+func (c *stubChecker) Run(newWorks []string, oldWorks []string) ([]*checker.ReportItem, error) {
+	time.Sleep(c.delay)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	roots := append(append([]string{}, newWorks...), oldWorks...)
+	ids := make([]uint64, len(roots))
+	for i, root := range roots {
+		ids[i], _ = strconv.ParseUint(path.Base(root), 10, 64)
+		content, err := os.ReadFile(path.Join(root, path.Base(root), "Main.cs"))
+		if err != nil {
+			return nil, err
+		}
+		c.files[ids[i]] = string(content)
+	}
+
+	var result []*checker.ReportItem
+	for i := range newWorks {
+		for j := i + 1; j < len(roots); j++ {
+			result = append(result, &checker.ReportItem{
+				Work1ID:    ids[i],
+				Work2ID:    ids[j],
+				Work1Dir:   path.Join(roots[i], path.Base(roots[i])),
+				Work2Dir:   path.Join(roots[j], path.Base(roots[j])),
+				Avg:        0.5,
+				Max:        0.5,
+				OffsetUnit: checker.UnitRunes,
+				Matches: []checker.MatchItem{
+					{Work1File: "Main.cs", Work1Start: 0, Work1Size: 5, Work2File: "Main.cs", Work2Start: 0, Work2Size: 5},
+				},
+			})
+		}
+	}
+	return result, nil
+}
+
+// testConfig возвращает конфигурацию приложения для подключения к серверу с ключом "secret".
+func testConfig(t *testing.T, server *orchestratortest.Server) config.Config {
+	return config.Config{
+		WorkDir:        t.TempDir(),
+		CheckerPlugin:  "stub",
+		CheckerLang:    "csharp",
+		OffsetUnit:     "runes",
+		TabWidth:       1,
+		StorageSize:    100,
+		MainServerHost: server.Addr(),
+		MainServerKey:  "secret",
+	}
+}
+
+// newTestApp запускает сервер Orchestrator в памяти и создаёт приложение, подключённое к нему.
+func newTestApp(t *testing.T, stub *stubChecker) (*appT, *orchestratortest.Server) {
+	t.Helper()
+
+	server := orchestratortest.NewServer()
+	server.SetKey("secret")
+	if err := server.Start(""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	application, err := Init(testConfig(t, server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = application.Close() })
+
+	a := application.(*appT)
+	a.taskChecker = stub
+	return a, server
+}
+
+func TestProcess(t *testing.T) {
+	stub := &stubChecker{files: make(map[uint64]string)}
+	a, server := newTestApp(t, stub)
+
+	works := map[uint64]string{
+		101: "class A { }",
+		102: "class B { }",
+		103: "class C { }",
+	}
+	for id, code := range works {
+		if err := server.AddWork(7, id, map[string]string{"Main.cs": code}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.AddTask(1, 7, 101, "csharp")
+	server.AddTask(2, 7, 102, "csharp")
+
+	if !a.process() {
+		t.Fatal("process: задачи не получены")
+	}
+
+	// Работы скачаны и распакованы.
+	for id, code := range works {
+		if stub.files[id] != code {
+			t.Errorf("работа %d: Main.cs = %q, ожидалось %q", id, stub.files[id], code)
+		}
+	}
+
+	// Отчёты по парам новых работ между собой и со старой работой.
+	var pairs [][2]uint64
+	for _, report := range server.Reports() {
+		// Порядок новых работ в задаче не определён: пара записывается по возрастанию id.
+		pair := [2]uint64{report.GetFirstWorkID(), report.GetSecondWorkID()}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		pairs = append(pairs, pair)
+
+		if report.GetFirstWorkEventID() != 7 || report.GetSecondWorkEventID() != 7 {
+			t.Errorf("отчёт %d/%d: события %d/%d, ожидалось 7", report.GetFirstWorkID(), report.GetSecondWorkID(),
+				report.GetFirstWorkEventID(), report.GetSecondWorkEventID())
+		}
+		if len(report.GetMatch()) != 1 || report.GetMatch()[0].GetFirstWorkPath() != "Main.cs" ||
+			report.GetMatch()[0].GetFirstWorkSize() != 5 {
+			t.Errorf("отчёт %d/%d: совпадения %v", report.GetFirstWorkID(), report.GetSecondWorkID(), report.GetMatch())
+		}
+	}
+	slices.SortFunc(pairs, func(x, y [2]uint64) int {
+		if x[0] != y[0] {
+			return int(x[0]) - int(y[0])
+		}
+		return int(x[1]) - int(y[1])
+	})
+	if want := [][2]uint64{{101, 102}, {101, 103}, {102, 103}}; !slices.Equal(pairs, want) {
+		t.Errorf("пары работ в отчётах %v, ожидалось %v", pairs, want)
+	}
+
+	// Задачи закрыты одним вызовом.
+	calls := server.CloseCalls()
+	if len(calls) != 1 || calls[0].WithError || !slices.Equal(calls[0].ID, []uint64{1, 2}) {
+		t.Errorf("закрытие задач %+v, ожидалось [{ID:[1 2] WithError:false}]", calls)
+	}
+	if status := server.TaskStatus(1); status != orchestratortest.StatusDone {
+		t.Errorf("состояние задачи 1: %s", status)
+	}
+
+	// Новых задач нет.
+	if a.process() {
+		t.Error("process: получены задачи после выполнения всех задач")
+	}
+}
+
+func TestProcessCheckerError(t *testing.T) {
+	stub := &stubChecker{files: make(map[uint64]string), err: errors.New("анализ не выполнен")}
+	a, server := newTestApp(t, stub)
+
+	for _, id := range []uint64{201, 202} {
+		if err := server.AddWork(8, id, map[string]string{"Main.cs": "class A { }"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.AddTask(3, 8, 201, "")
+
+	if !a.process() {
+		t.Fatal("process: задачи не получены")
+	}
+
+	if reports := server.Reports(); len(reports) != 0 {
+		t.Errorf("отправлено отчётов: %d, ожидалось 0", len(reports))
+	}
+	calls := server.CloseCalls()
+	if len(calls) != 1 || !calls[0].WithError || !slices.Equal(calls[0].ID, []uint64{3}) {
+		t.Errorf("закрытие задач %+v, ожидалось [{ID:[3] WithError:true}]", calls)
+	}
+}
+
+func TestProcessSingleWork(t *testing.T) {
+	stub := &stubChecker{files: make(map[uint64]string)}
+	a, server := newTestApp(t, stub)
+
+	if err := server.AddWork(9, 301, map[string]string{"Main.cs": "class A { }"}); err != nil {
+		t.Fatal(err)
+	}
+	server.AddTask(4, 9, 301, "")
+
+	if !a.process() {
+		t.Fatal("process: задачи не получены")
+	}
+
+	// Единственную работу не с чем сравнивать: задача закрывается без анализа.
+	if len(stub.files) != 0 {
+		t.Error("анализатор запущен для единственной работы")
+	}
+	calls := server.CloseCalls()
+	if len(calls) != 1 || calls[0].WithError {
+		t.Errorf("закрытие задач %+v, ожидалось [{ID:[4] WithError:false}]", calls)
+	}
+}
+
+func TestProcessLease(t *testing.T) {
+	stub := &stubChecker{files: make(map[uint64]string), delay: 1500 * time.Millisecond}
+	a, server := newTestApp(t, stub)
+	server.SetLease(time.Second)
+
+	for _, id := range []uint64{401, 402} {
+		if err := server.AddWork(10, id, map[string]string{"Main.cs": "class A { }"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.AddTask(5, 10, 401, "")
+
+	if !a.process() {
+		t.Fatal("process: задачи не получены")
+	}
+
+	// Анализ дольше времени аренды: аренда продлевается, задача не передаётся повторно.
+	calls := server.LeaseCalls()
+	if len(calls) < 3 {
+		t.Errorf("продлений аренды: %d, ожидалось не меньше 3", len(calls))
+	}
+	for _, ids := range calls {
+		if !slices.Equal(ids, []uint64{5}) {
+			t.Errorf("продление аренды задач %v, ожидалось [5]", ids)
+		}
+	}
+	if status := server.TaskStatus(5); status != orchestratortest.StatusDone {
+		t.Errorf("состояние задачи 5: %s", status)
+	}
+
+	// После закрытия задачи аренда не продлевается.
+	count := len(server.LeaseCalls())
+	time.Sleep(500 * time.Millisecond)
+	if len(server.LeaseCalls()) != count {
+		t.Error("аренда продлевается после завершения задачи")
+	}
+}
+
+func TestProcessLeaseLost(t *testing.T) {
+	stub := &stubChecker{files: make(map[uint64]string), delay: 1500 * time.Millisecond}
+	a, server := newTestApp(t, stub)
+	server.SetLease(time.Second)
+
+	for _, id := range []uint64{401, 402, 403} {
+		if err := server.AddWork(10, id, map[string]string{"Main.cs": "class A { }"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.AddTask(5, 10, 401, "")
+	server.AddTask(6, 10, 402, "")
+
+	// Во время анализа сервер передаёт задачу 6 другому раннеру.
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		server.ReassignTask(6)
+	}()
+
+	if !a.process() {
+		t.Fatal("process: задачи не получены")
+	}
+
+	// Отчёты отправлены только по парам с работой задачи 5.
+	for _, report := range server.Reports() {
+		if report.GetFirstWorkID() != 401 && report.GetSecondWorkID() != 401 {
+			t.Errorf("отправлен отчёт %d/%d задачи с истёкшей арендой", report.GetFirstWorkID(), report.GetSecondWorkID())
+		}
+	}
+	if len(server.Reports()) != 2 {
+		t.Errorf("отчётов: %d, ожидалось 2", len(server.Reports()))
+	}
+
+	// Закрыта только задача 5, задача 6 ожидает другого раннера.
+	calls := server.CloseCalls()
+	if len(calls) != 1 || calls[0].WithError || !slices.Equal(calls[0].ID, []uint64{5}) {
+		t.Errorf("закрытие задач %+v, ожидалось [{ID:[5] WithError:false}]", calls)
+	}
+	if status := server.TaskStatus(6); status != orchestratortest.StatusNew {
+		t.Errorf("состояние задачи 6: %s", status)
+	}
+}
+
+func TestRemoveOldOutputs(t *testing.T) {
+	workDir := t.TempDir()
+	a := &appT{
+		cfg: config.Config{
+			WorkDir:     workDir,
+			HTMLReports: true,
+			CaptureDir:  path.Join(workDir, "capture"),
+			KeepDays:    2,
+		},
+		exports: []export.Format{export.FormatCSV},
+		capture: &capture.Recorder{},
+	}
+
+	old := time.Now().Add(-72 * time.Hour)
+	create := func(name string, dir bool, modTime time.Time) string {
+		p := path.Join(workDir, name)
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		if dir {
+			err = os.MkdirAll(p, 0755)
+		} else {
+			err = os.WriteFile(p, nil, 0644)
+		}
+		if err == nil {
+			err = os.Chtimes(p, modTime, modTime)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	removed := []string{
+		create("reports/7/101_102.html", false, old),
+		create("exports/7/20240101_120000_1.csv", false, old),
+		create("capture/7/20240101_120000_1", true, old),
+	}
+	kept := []string{
+		create("reports/7/101_103.html", false, time.Now()),
+		create("capture/8/20240105_120000_2", true, time.Now()),
+		// Каталоги, которые не относятся к отчётам, не очищаются.
+		create("storage/7/101.zip", false, old),
+	}
+
+	a.removeOldOutputs()
+
+	for _, p := range removed {
+		if _, err := os.Stat(p); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s не удалён", p)
+		}
+	}
+	for _, p := range kept {
+		if _, err := os.Stat(p); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Опустевшие каталоги событий удаляются.
+	if _, err := os.Stat(path.Join(workDir, "exports", "7")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("каталог exports/7 не удалён")
+	}
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"CodeBorrowing/internal/capture"
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/export"
+	"CodeBorrowing/internal/logger"
+	"CodeBorrowing/internal/preprocess"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+	"os"
+	"path"
+)
+
+// ReplayOptions - параметры воспроизведения задачи из пакета.
+type ReplayOptions struct {
+	Bundle   string        // Каталог пакета воспроизведения.
+	WorkDir  string        // Каталог для временных файлов и логов.
+	Output   io.Writer     // Поток вывода отчёта.
+	Format   export.Format // Формат отчёта.
+	HTMLDir  string        // Каталог для отчётов HTML по парам работ (пусто - не сохраняются).
+	Requests io.Writer     // Поток вывода отчётов для сервера в формате JSON Lines (nil - не выводятся).
+}
+
+// Replay воспроизводит разбор результата анализа и формирование отчётов по пакету задачи
+// без запуска анализатора и подключения к серверу. Параметры анализа берутся из пакета.
+// Логи выводятся в stderr и в каталог логов options.WorkDir.
+func Replay(options ReplayOptions) error {
+	appLogger := logger.NewLoggerWithConsole(path.Join(options.WorkDir, "logs"), os.Stderr)
+	defer appLogger.Close()
+
+	bundle, err := capture.Load(options.Bundle)
+	if err != nil {
+		return err
+	}
+	if bundle.Error != "" {
+		appLogger.Warnf("Анализ задачи завершился с ошибкой: %s", bundle.Error)
+	}
+	output := path.Join(options.Bundle, bundle.Output)
+	if _, err = os.Stat(output); err != nil {
+		return fmt.Errorf("результат анализатора отсутствует в пакете: %v", err)
+	}
+
+	// Каталоги работ и сверка файлов с сохранёнными хэшами.
+	newWorks, oldWorks, changes, err := bundle.Roots(options.Bundle)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		appLogger.Warnf("Файлы работы отличаются от анализированных: %s", change)
+	}
+
+	// Анализатор с параметрами пакета.
+	capturer, err := replayChecker(bundle.Checker, options.WorkDir, appLogger)
+	if err != nil {
+		return err
+	}
+
+	a := &appT{logger: appLogger}
+	if bundle.Preprocess != nil {
+		a.preprocess = preprocess.NewPipeline(appLogger, path.Join(options.WorkDir, "prepared"), *bundle.Preprocess)
+	}
+
+	// Разбор результата анализа.
+	var ignore *preprocess.Ignore
+	if bundle.Ignore != "" {
+		ignore = preprocess.ParseIgnore(bundle.Ignore)
+	}
+	result, err := a.analyze(ignore, newWorks, oldWorks, func(newWorks []string, oldWorks []string) ([]*checker.ReportItem, error) {
+		return capturer.Replay(newWorks, oldWorks, output)
+	})
+	if err != nil {
+		return err
+	}
+	checker.MergeMatches(result, bundle.Merge)
+	appLogger.Infof("Результат анализа разобран (пар работ: %d)", len(result))
+
+	// События работ.
+	events := bundle.WorkEvents()
+	for _, res := range result {
+		res.Work1EventID, res.Work2EventID = bundle.EventID, bundle.EventID
+		if eventID, ok := events[res.Work1ID]; ok {
+			res.Work1EventID = eventID
+		}
+		if eventID, ok := events[res.Work2ID]; ok {
+			res.Work2EventID = eventID
+		}
+	}
+
+	// Отчёты для сервера.
+	if options.Requests != nil {
+		if err = writeRequests(options.Requests, result, bundle.Tasks, appLogger); err != nil {
+			return err
+		}
+	}
+
+	// Отчёты HTML по парам работ.
+	if options.HTMLDir != "" {
+		if err = export.SaveHTML(options.HTMLDir, result); err != nil {
+			return err
+		}
+		appLogger.Infof("Отчёты HTML сохранены в %s", options.HTMLDir)
+	}
+
+	var info checker.RunInfo
+	if provider, ok := capturer.(checker.RunInfoProvider); ok {
+		info = provider.LastRunInfo()
+	}
+
+	return export.Write(options.Output, options.Format, info, result)
+}
+
+// replayChecker создаёт анализатор для разбора сохранённого результата.
+func replayChecker(settings capture.Checker, workDir string, appLogger *logger.Logger) (checker.Capturer, error) {
+	options := checker.Options{
+		Language:   settings.Language,
+		OffsetUnit: settings.OffsetUnit,
+		TabWidth:   settings.TabWidth,
+	}
+
+	var c checker.Checker
+	switch settings.Engine {
+	case capture.EngineJplag:
+		c = checker.NewJplagChecker(appLogger, settings.Path, path.Join(workDir, "check", "01"), "", 0, options)
+	case capture.EnginePlugin:
+		c = checker.NewPluginChecker(appLogger, settings.Path, options)
+	}
+
+	capturer, ok := c.(checker.Capturer)
+	if !ok {
+		return nil, errors.New("анализатор пакета не поддерживает воспроизведение")
+	}
+	return capturer, nil
+}
+
+// writeRequests записывает отчёты о парах работ в том виде, в котором они отправляются серверу, по отчёту в строке.
+// Как и при выполнении задачи, пары работ без новых работ задач tasks не записываются.
+func writeRequests(w io.Writer, result []*checker.ReportItem, tasks []capture.Task, appLogger *logger.Logger) error {
+	tasksWorksID := make(map[uint64]any, len(tasks))
+	for _, t := range tasks {
+		tasksWorksID[t.WorkID] = nil
+	}
+
+	for _, res := range result {
+		// Работы, не сопоставленные с id, не отправляются.
+		if res.Work1ID == 0 || res.Work2ID == 0 {
+			appLogger.Errorf("Не удалось определить id работ %s и %s", res.Work1Name, res.Work2Name)
+			continue
+		}
+
+		_, ok1 := tasksWorksID[res.Work1ID]
+		_, ok2 := tasksWorksID[res.Work2ID]
+		if !ok1 && !ok2 {
+			continue
+		}
+
+		content, err := protojson.Marshal(newReportRequest(res))
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "%s\n", content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
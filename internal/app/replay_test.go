@@ -0,0 +1,168 @@
+package app
+
+import (
+	"CodeBorrowing/internal/capture"
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/export"
+	"CodeBorrowing/internal/orchestratortest"
+	"CodeBorrowing/services/orchestrator"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"path"
+	"testing"
+)
+
+// Переменная среды, при которой тестовый исполняемый файл работает как внешний анализатор.
+const envTestPlugin = "CODEBORROWING_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestPlugin) != "" {
+		os.Exit(runTestPlugin())
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin - внешний анализатор для тестов: сравнивает все работы запроса попарно
+// и сообщает одно совпадение в файле Main.cs.
+func runTestPlugin() int {
+	var request checker.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		return 1
+	}
+
+	var works []checker.PluginWork
+	for _, root := range append(append([]string{}, request.New...), request.Old...) {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return 1
+		}
+		for _, entry := range entries {
+			works = append(works, checker.PluginWork{Root: root, Name: entry.Name()})
+		}
+	}
+
+	response := checker.PluginResponse{Version: checker.PluginProtocolVersion}
+	for i := range works {
+		for j := i + 1; j < len(works); j++ {
+			response.Pairs = append(response.Pairs, checker.PluginPair{
+				Work1: works[i],
+				Work2: works[j],
+				Avg:   0.5,
+				Max:   0.75,
+				Matches: []checker.MatchItem{
+					{Work1File: "Main.cs", Work1Start: 0, Work1Size: 5, Work2File: "Main.cs", Work2Start: 2, Work2Size: 5},
+				},
+			})
+		}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(response); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// TestCaptureReplay проверяет, что воспроизведение пакета задачи формирует те же отчёты,
+// что были отправлены серверу, без исходных каталогов работ.
+func TestCaptureReplay(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envTestPlugin, "1")
+
+	server := orchestratortest.NewServer()
+	server.SetKey("secret")
+	if err = server.Start(""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	cfg := testConfig(t, server)
+	cfg.CheckerPlugin = executable
+	cfg.CaptureDir = path.Join(t.TempDir(), "capture")
+	cfg.CaptureWorks = true
+
+	application, err := Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id, code := range map[uint64]string{101: "class A { }", 102: "class B { }", 103: "class C { }"} {
+		if err = server.AddWork(7, id, map[string]string{"Main.cs": code}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.AddTask(1, 7, 101, "csharp")
+
+	if !application.(*appT).process() {
+		t.Fatal("process: задачи не получены")
+	}
+	_ = application.Close()
+
+	reports := server.Reports()
+	if len(reports) != 2 {
+		t.Fatalf("отчётов %d, ожидалось 2", len(reports))
+	}
+
+	// Пакет задачи сохранён в <captureDir>/<eventID>/<время>_<id задачи>.
+	entries, err := os.ReadDir(path.Join(cfg.CaptureDir, "7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("пакетов %d, ожидался 1", len(entries))
+	}
+	bundle := path.Join(cfg.CaptureDir, "7", entries[0].Name())
+	if _, err = os.Stat(path.Join(bundle, capture.BundleFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Хранилище работ удалено: воспроизведение использует копии работ из пакета.
+	if err = os.RemoveAll(cfg.WorkDir); err != nil {
+		t.Fatal(err)
+	}
+
+	var output, requests bytes.Buffer
+	err = Replay(ReplayOptions{
+		Bundle:   bundle,
+		WorkDir:  t.TempDir(),
+		Output:   &output,
+		Format:   export.FormatJSONL,
+		Requests: &requests,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Отчёты для сервера совпадают с отправленными.
+	var replayed []*orchestrator.SendCrossCheckReportRequest
+	scanner := bufio.NewScanner(&requests)
+	for scanner.Scan() {
+		request := &orchestrator.SendCrossCheckReportRequest{}
+		if err = protojson.Unmarshal(scanner.Bytes(), request); err != nil {
+			t.Fatal(err)
+		}
+		replayed = append(replayed, request)
+	}
+	if len(replayed) != len(reports) {
+		t.Fatalf("воспроизведено отчётов %d, ожидалось %d", len(replayed), len(reports))
+	}
+	for _, report := range reports {
+		found := false
+		for _, request := range replayed {
+			found = found || proto.Equal(report, request)
+		}
+		if !found {
+			t.Errorf("отчёт %v не воспроизведён", report)
+		}
+	}
+
+	// Отчёт в формате jsonl содержит все пары работ результата анализа, в том числе пару старых работ.
+	if lines := bytes.Count(output.Bytes(), []byte("\n")); lines != 3 {
+		t.Errorf("строк отчёта %d, ожидалось 3", lines)
+	}
+}
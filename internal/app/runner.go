@@ -0,0 +1,91 @@
+package app
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/config"
+	"CodeBorrowing/internal/logger"
+	"CodeBorrowing/services/orchestrator"
+	"context"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Время ожидания ответа сервера при запуске.
+const startupTimeout = 30 * time.Second
+
+// Количество задач, выполняемых раннером одновременно.
+const runnerConcurrency = 1
+
+// getRunnerInfo получает от сервера сведения о раннере: id, название и тег.
+func getRunnerInfo(client orchestrator.OrchestratorClient) (*orchestrator.Runner, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), startupTimeout)
+	defer cancel()
+
+	resp, err := client.GetRunnerInfo(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("grpc: не удалось получить сведения о раннере: %v", err)
+	}
+
+	return resp.GetRunner(), nil
+}
+
+// registerCapabilities сообщает серверу возможности раннера: версию, анализатор, язык проверяемых работ,
+// количество одновременно выполняемых задач и единицу измерения позиций в отчётах.
+// Сообщается только язык из конфигурации: анализ выполняется на нём, а не на всех языках анализатора.
+// Ошибка не прерывает запуск: сервер, не поддерживающий регистрацию, выдаёт задачи без учёта возможностей.
+func registerCapabilities(client orchestrator.OrchestratorClient, appLogger *logger.Logger,
+	cfg config.Config, capabilities checker.Capabilities) {
+
+	engine := &orchestrator.CheckerEngine{
+		Name:     capabilities.Engine,
+		Language: []string{cfg.CheckerLang},
+	}
+	if capabilities.Version != (checker.Version{}) {
+		engine.Version = capabilities.Version.String()
+	}
+
+	req := &orchestrator.RegisterRunnerCapabilitiesRequest{
+		Version:     runnerVersion(),
+		Engine:      []*orchestrator.CheckerEngine{engine},
+		Concurrency: runnerConcurrency,
+		OffsetUnit:  offsetUnits[checker.OffsetUnit(cfg.OffsetUnit)],
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), startupTimeout)
+	defer cancel()
+
+	_, err := client.RegisterRunnerCapabilities(ctx, req)
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		appLogger.Warnf("Сервер не поддерживает регистрацию возможностей раннера")
+	case err != nil:
+		appLogger.Warnf("Не удалось сообщить серверу возможности раннера: %v", err)
+	default:
+		appLogger.Infof("Возможности раннера: версия=%s, анализатор=%s, языки=%v",
+			req.GetVersion(), strings.TrimSpace(engine.GetName()+" "+engine.GetVersion()), engine.GetLanguage())
+	}
+}
+
+// runnerVersion возвращает версию раннера из сведений о сборке: версию модуля
+// или ревизию системы контроля версий для сборки из исходного кода.
+func runnerVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return info.Main.Version
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"CodeBorrowing/internal/orchestratortest"
+	"CodeBorrowing/services/orchestrator"
+	"archive/zip"
+	"os"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestInitRunner(t *testing.T) {
+	server := orchestratortest.NewServer()
+	server.SetKey("secret")
+	server.SetRunner(&orchestrator.Runner{ID: 3, Name: "runner-3", Tag: "python"})
+	if err := server.Start(""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	// Замена языка и ширины табуляции для тега python.
+	t.Setenv("checkerLang_python", "python3")
+	t.Setenv("tabWidth_python", "4")
+	t.Setenv("mainServerHost_python", "127.0.0.1:1") // параметры подключения по тегу не заменяются.
+
+	cfg := testConfig(t, server)
+	cfg.MatchGap = 5 // параметры без замены по тегу сохраняются.
+	application, err := Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = application.Close() })
+	a := application.(*appT)
+
+	if a.cfg.CheckerLang != "python3" || a.cfg.TabWidth != 4 {
+		t.Errorf("параметры для тега: язык=%s, ширина табуляции=%d, ожидалось python3 и 4", a.cfg.CheckerLang, a.cfg.TabWidth)
+	}
+	if a.cfg.CheckerPlugin != "stub" || a.cfg.StorageSize != 100 || a.cfg.MatchGap != 5 {
+		t.Errorf("параметры без замены по тегу изменены: %+v", a.cfg)
+	}
+	if a.cfg.MainServerHost != server.Addr() {
+		t.Errorf("адрес сервера заменён по тегу: %s", a.cfg.MainServerHost)
+	}
+
+	capabilities := server.Capabilities()
+	if capabilities == nil {
+		t.Fatal("возможности раннера не зарегистрированы")
+	}
+	if len(capabilities.GetEngine()) != 1 || capabilities.GetEngine()[0].GetName() != "plugin" ||
+		!slices.Equal(capabilities.GetEngine()[0].GetLanguage(), []string{"python3"}) {
+		t.Errorf("анализаторы раннера %v, ожидался plugin с языком python3", capabilities.GetEngine())
+	}
+	if capabilities.GetConcurrency() != 1 || capabilities.GetOffsetUnit() != orchestrator.OffsetUnit_OFFSET_UNIT_RUNES {
+		t.Errorf("возможности раннера %v", capabilities)
+	}
+}
+
+// TestInitJplagLanguage проверяет, что язык, не найденный в jar-архиве Jplag, не прерывает запуск.
+func TestInitJplagLanguage(t *testing.T) {
+	server := orchestratortest.NewServer()
+	server.SetKey("secret")
+	if err := server.Start(""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	jarPath := path.Join(t.TempDir(), "jplag.jar")
+	f, err := os.Create(jarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	fw, err := w.Create("META-INF/services/de.jplag.Language")
+	if err == nil {
+		_, err = fw.Write([]byte("de.jplag.java.JavaLanguage\n"))
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	_ = f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig(t, server)
+	cfg.CheckerPlugin = ""
+	cfg.CheckerPath = jarPath
+	cfg.CheckerLang = "kotlin"
+	application, err := Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = application.Close()
+
+	capabilities := server.Capabilities()
+	if capabilities == nil || !slices.Equal(capabilities.GetEngine()[0].GetLanguage(), []string{"kotlin"}) {
+		t.Errorf("возможности раннера %v, ожидался язык kotlin", capabilities)
+	}
+}
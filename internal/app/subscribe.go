@@ -0,0 +1,58 @@
+package app
+
+import (
+	"CodeBorrowing/internal/task"
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Задержки повторного подключения к потоку уведомлений о задачах.
+const (
+	subscribeMinBackoff = time.Second
+	subscribeMaxBackoff = time.Minute
+)
+
+// Интервал проверки задач при подключённом потоке уведомлений: на случай пропущенного уведомления.
+const subscribedPollInterval = time.Minute
+
+// subscribeTasks получает уведомления о новых задачах от сервера и передаёт их в notify.
+// При разрыве потока подключение повторяется с растущей задержкой. Если сервер не поддерживает
+// уведомления, функция завершается: задачи проверяются опросом.
+// Subscribed: подключён ли поток (сервер прислал хотя бы одно уведомление).
+func (a *appT) subscribeTasks(ctx context.Context, notify chan<- struct{}, subscribed *atomic.Bool) {
+	backoff := subscribeMinBackoff
+	for {
+		err := a.taskService.SubscribeTasks(ctx, func(eventID uint64) {
+			if !subscribed.Swap(true) {
+				a.logger.Info("Подключён поток уведомлений о новых задачах")
+			}
+			a.logger.Debugf("Уведомление о новых задачах (eventId=%d)", eventID)
+			backoff = subscribeMinBackoff
+
+			// Уведомления, пришедшие во время выполнения задачи, объединяются.
+			select {
+			case notify <- struct{}{}:
+			default:
+			}
+		})
+		subscribed.Store(false)
+
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, task.ErrSubscribeUnsupported) {
+			a.logger.Info("Сервер не поддерживает уведомления о задачах: задачи проверяются опросом")
+			return
+		}
+
+		a.logger.Warnf("Поток уведомлений о задачах прерван: %v. Повторное подключение через %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, subscribeMaxBackoff)
+	}
+}
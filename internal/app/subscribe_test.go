@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitNotify ожидает уведомление о новых задачах.
+func waitNotify(t *testing.T, notify <-chan struct{}, message string) {
+	t.Helper()
+
+	select {
+	case <-notify:
+	case <-time.After(5 * time.Second):
+		t.Fatal(message)
+	}
+}
+
+func TestSubscribeTasks(t *testing.T) {
+	a, server := newTestApp(t, &stubChecker{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notify := make(chan struct{}, 1)
+	subscribed := &atomic.Bool{}
+	done := make(chan struct{})
+	go func() {
+		a.subscribeTasks(ctx, notify, subscribed)
+		close(done)
+	}()
+
+	// Уведомление сразу после подписки и о новой задаче.
+	waitNotify(t, notify, "нет уведомления после подписки")
+	if !subscribed.Load() {
+		t.Error("поток уведомлений не отмечен подключённым")
+	}
+	server.AddTask(1, 7, 101, "")
+	waitNotify(t, notify, "нет уведомления о новой задаче")
+
+	// После разрыва потока подписка восстанавливается.
+	server.DropSubscribers()
+	waitNotify(t, notify, "нет уведомления после повторного подключения")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("подписка не завершена после отмены")
+	}
+	if subscribed.Load() {
+		t.Error("поток уведомлений отмечен подключённым после отмены")
+	}
+}
+
+func TestSubscribeTasksUnsupported(t *testing.T) {
+	a, server := newTestApp(t, &stubChecker{})
+	server.DisableSubscribe()
+
+	done := make(chan struct{})
+	subscribed := &atomic.Bool{}
+	go func() {
+		a.subscribeTasks(context.Background(), make(chan struct{}, 1), subscribed)
+		close(done)
+	}()
+
+	// Сервер без поддержки уведомлений: подписка завершается, задачи проверяются опросом.
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("подписка не завершена, хотя сервер не поддерживает уведомления")
+	}
+	if subscribed.Load() {
+		t.Error("поток уведомлений отмечен подключённым")
+	}
+}
@@ -0,0 +1,218 @@
+package capture
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/preprocess"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// SchemaVersion версия формата пакета воспроизведения.
+const SchemaVersion = 1
+
+// BundleFile название файла описания пакета.
+const BundleFile = "bundle.json"
+
+// Анализаторы работ.
+const (
+	EngineJplag  = "jplag"  // Jplag: исходный результат - результирующий архив.
+	EnginePlugin = "plugin" // внешний анализатор: исходный результат - запрос и ответ анализатора.
+)
+
+// Названия файлов исходного результата анализатора в пакете.
+var outputFiles = map[string]string{
+	EngineJplag:  "result.zip",
+	EnginePlugin: "plugin.json",
+}
+
+// Bundle - пакет воспроизведения задачи: задачи, работы, параметры и исходный результат анализатора.
+//
+// Пакет хранится в каталоге:
+//
+//	bundle.json          - описание пакета;
+//	works/<i>/<каталог>  - копии каталогов работ (если включено копирование);
+//	result.zip           - результирующий архив Jplag или plugin.json - запрос и ответ внешнего анализатора.
+type Bundle struct {
+	Schema     int                  `json:"schema"`
+	Created    time.Time            `json:"created"`
+	EventID    uint64               `json:"event_id"`
+	Tasks      []Task               `json:"tasks"`
+	New        []Work               `json:"new"`
+	Old        []Work               `json:"old"`
+	Checker    Checker              `json:"checker"`
+	Preprocess *preprocess.Options  `json:"preprocess,omitempty"` // nil - подготовка исходного кода не выполнялась.
+	Ignore     string               `json:"ignore,omitempty"`     // правила игнорирования файлов в формате .gitignore.
+	Merge      checker.MergeOptions `json:"merge"`
+	Output     string               `json:"output"`          // файл исходного результата анализатора в пакете.
+	Error      string               `json:"error,omitempty"` // ошибка анализа.
+}
+
+// Task - задача пакета.
+type Task struct {
+	ID     uint64 `json:"id"`
+	WorkID uint64 `json:"work_id"`
+	Tag    string `json:"tag"`
+}
+
+// Work - каталог работы, переданный анализатору.
+type Work struct {
+	WorkID  uint64            `json:"work_id"`
+	EventID uint64            `json:"event_id"`
+	Root    string            `json:"root"`           // исходный каталог.
+	Copy    string            `json:"copy,omitempty"` // копия каталога относительно пакета (пусто - не копировался).
+	Files   map[string]string `json:"files"`          // хэши SHA-256 файлов по пути относительно каталога.
+}
+
+// Checker - параметры анализатора.
+type Checker struct {
+	Engine     string             `json:"engine"`
+	Path       string             `json:"path"`
+	Language   string             `json:"language"`
+	OffsetUnit checker.OffsetUnit `json:"offset_unit"`
+	TabWidth   int                `json:"tab_width"`
+	Info       checker.RunInfo    `json:"info"`
+}
+
+// Load читает пакет воспроизведения из каталога dir.
+func Load(dir string) (*Bundle, error) {
+	content, err := os.ReadFile(path.Join(dir, BundleFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var bundle Bundle
+	if err = json.Unmarshal(content, &bundle); err != nil {
+		return nil, fmt.Errorf("%s: %v", BundleFile, err)
+	}
+	if bundle.Schema != SchemaVersion {
+		return nil, fmt.Errorf("%s: неподдерживаемая версия пакета %d", BundleFile, bundle.Schema)
+	}
+	if _, ok := outputFiles[bundle.Checker.Engine]; !ok {
+		return nil, fmt.Errorf("%s: неизвестный анализатор \"%s\"", BundleFile, bundle.Checker.Engine)
+	}
+
+	return &bundle, nil
+}
+
+// Roots возвращает каталоги работ для воспроизведения: копии из пакета dir или исходные каталоги.
+// Файлы каталогов сверяются с сохранёнными хэшами, расхождения возвращаются в виде списка сообщений.
+func (b *Bundle) Roots(dir string) (newWorks []string, oldWorks []string, changes []string, err error) {
+	roots := func(works []Work) ([]string, error) {
+		result := make([]string, 0, len(works))
+		for _, work := range works {
+			root := work.Root
+			if work.Copy != "" {
+				root = path.Join(dir, work.Copy)
+			}
+
+			diff, err := verify(root, work.Files)
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range diff {
+				changes = append(changes, fmt.Sprintf("работа %d (%s): %s", work.WorkID, root, d))
+			}
+
+			result = append(result, root)
+		}
+		return result, nil
+	}
+
+	if newWorks, err = roots(b.New); err != nil {
+		return nil, nil, nil, err
+	}
+	if oldWorks, err = roots(b.Old); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return newWorks, oldWorks, changes, nil
+}
+
+// WorkEvents возвращает события работ пакета по их id.
+func (b *Bundle) WorkEvents() map[uint64]uint64 {
+	result := make(map[uint64]uint64, len(b.New)+len(b.Old))
+	for _, work := range append(append([]Work{}, b.New...), b.Old...) {
+		result[work.WorkID] = work.EventID
+	}
+	return result
+}
+
+// hashFiles вычисляет хэши SHA-256 файлов каталога root по пути относительно каталога.
+func hashFiles(root string) (map[string]string, error) {
+	result := make(map[string]string)
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+
+		hash, err := hashFile(filePath)
+		if err != nil {
+			return err
+		}
+		result[filepath.ToSlash(rel)] = hash
+		return nil
+	})
+
+	return result, err
+}
+
+// hashFile вычисляет хэш SHA-256 файла.
+func hashFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verify сверяет файлы каталога root с хэшами files. Возвращает описания расхождений.
+func verify(root string, files map[string]string) ([]string, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	current, err := hashFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff []string
+	for rel, hash := range files {
+		if h, ok := current[rel]; !ok {
+			diff = append(diff, "отсутствует файл "+rel)
+		} else if h != hash {
+			diff = append(diff, "изменён файл "+rel)
+		}
+	}
+	for rel := range current {
+		if _, ok := files[rel]; !ok {
+			diff = append(diff, "добавлен файл "+rel)
+		}
+	}
+	slices.Sort(diff)
+
+	return diff, nil
+}
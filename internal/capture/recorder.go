@@ -0,0 +1,131 @@
+package capture
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/preprocess"
+	"CodeBorrowing/internal/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+// Recorder - запись пакетов воспроизведения задач.
+type Recorder struct {
+	dir        string
+	copyWorks  bool
+	checker    Checker
+	preprocess *preprocess.Options
+	merge      checker.MergeOptions
+}
+
+// Capture - записываемый пакет воспроизведения задачи.
+type Capture struct {
+	dir    string
+	bundle Bundle
+}
+
+// NewRecorder создаёт запись пакетов воспроизведения в каталог dir.
+// CopyWorks: копировать работы в пакет (иначе сохраняются только хэши файлов).
+// Analyzer, preprocess, merge: параметры анализатора, подготовки исходного кода (nil - не выполняется)
+// и объединения фрагментов совпадений.
+func NewRecorder(dir string, copyWorks bool, analyzer Checker,
+	preprocess *preprocess.Options, merge checker.MergeOptions) *Recorder {
+	return &Recorder{
+		dir:        dir,
+		copyWorks:  copyWorks,
+		checker:    analyzer,
+		preprocess: preprocess,
+		merge:      merge,
+	}
+}
+
+// Begin создаёт пакет задачи в каталоге <dir>/<eventID>/<время>_<id первой задачи>
+// и сохраняет копии или хэши файлов работ.
+// NewWorks, oldWorks: каталоги работ в порядке передачи анализатору. Ignore: правила игнорирования файлов.
+func (r *Recorder) Begin(eventID uint64, tasks []Task, newWorks []Work, oldWorks []Work, ignore *preprocess.Ignore) (*Capture, error) {
+	name := time.Now().UTC().Format("20060102_150405")
+	if len(tasks) != 0 {
+		name += "_" + strconv.FormatUint(tasks[0].ID, 10)
+	}
+
+	c := &Capture{
+		dir: path.Join(r.dir, strconv.FormatUint(eventID, 10), name),
+		bundle: Bundle{
+			Schema:     SchemaVersion,
+			Created:    time.Now().UTC(),
+			EventID:    eventID,
+			Tasks:      tasks,
+			Checker:    r.checker,
+			Preprocess: r.preprocess,
+			Ignore:     ignore.String(),
+			Merge:      r.merge,
+			Output:     outputFiles[r.checker.Engine],
+		},
+	}
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	// Работы нумеруются в порядке передачи анализатору: сначала новые, затем старые.
+	var err error
+	if c.bundle.New, err = r.saveWorks(c.dir, newWorks, 0); err != nil {
+		return nil, err
+	}
+	if c.bundle.Old, err = r.saveWorks(c.dir, oldWorks, len(newWorks)); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// saveWorks сохраняет хэши файлов работ и, если включено, копии каталогов works/<номер>/<каталог>.
+// First: номер первой работы.
+func (r *Recorder) saveWorks(dir string, works []Work, first int) ([]Work, error) {
+	result := make([]Work, 0, len(works))
+	for i, work := range works {
+		files, err := hashFiles(work.Root)
+		if err != nil {
+			return nil, fmt.Errorf("работа %d: %v", work.WorkID, err)
+		}
+		work.Files = files
+
+		if r.copyWorks {
+			work.Copy = path.Join("works", strconv.Itoa(first+i), path.Base(work.Root))
+			if err = utils.CopyDirectory(work.Root, path.Join(dir, work.Copy)); err != nil {
+				return nil, fmt.Errorf("работа %d: %v", work.WorkID, err)
+			}
+		}
+
+		result = append(result, work)
+	}
+
+	return result, nil
+}
+
+// Dir возвращает каталог пакета.
+func (c *Capture) Dir() string {
+	return c.dir
+}
+
+// Output возвращает путь к файлу для исходного результата анализатора.
+func (c *Capture) Output() string {
+	return path.Join(c.dir, c.bundle.Output)
+}
+
+// Finish сохраняет описание пакета.
+// Info: сведения о запуске анализа. CheckErr: ошибка анализа (nil - анализ выполнен).
+func (c *Capture) Finish(info checker.RunInfo, checkErr error) error {
+	c.bundle.Checker.Info = info
+	if checkErr != nil {
+		c.bundle.Error = checkErr.Error()
+	}
+
+	content, err := json.MarshalIndent(c.bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(c.dir, BundleFile), content, 0644)
+}
@@ -2,10 +2,19 @@ package checker
 
 import (
 	"errors"
+	"os"
 )
 
 var ErrNoNewWork = errors.New("не указан путь до новой работы")
 var ErrNoWorks = errors.New("нет работ для сравнения")
+var ErrUnknownReport = errors.New("неизвестный формат отчёта анализатора")
+
+// Options - параметры анализа.
+type Options struct {
+	Language   string     // Язык анализируемых работ.
+	OffsetUnit OffsetUnit // Единица измерения позиций фрагментов в отчёте.
+	TabWidth   int        // Ширина табуляции в столбцах анализатора.
+}
 
 type Checker interface {
 	// Run запускает анализ работ.
@@ -13,3 +22,48 @@ type Checker interface {
 	// oldWorks - путь к каталогам со старыми работами.
 	Run(newWorks []string, oldWorks []string) ([]*ReportItem, error)
 }
+
+// RunInfoProvider - анализатор, сообщающий сведения о последнем запуске.
+type RunInfoProvider interface {
+	// LastRunInfo возвращает сведения о последнем запуске анализа.
+	LastRunInfo() RunInfo
+}
+
+// Capturer - анализатор, исходный результат которого можно сохранить и разобрать повторно.
+type Capturer interface {
+	// RunCapture запускает анализ работ и сохраняет исходный результат анализатора в файл output.
+	RunCapture(newWorks []string, oldWorks []string, output string) ([]*ReportItem, error)
+
+	// Replay разбирает исходный результат анализатора из файла output без запуска анализа.
+	// NewWorks, oldWorks - каталоги с работами в том же порядке, что и при анализе
+	// (пути могут отличаться, названия каталогов должны совпадать).
+	Replay(newWorks []string, oldWorks []string, output string) ([]*ReportItem, error)
+}
+
+// checkWorks проверяет, что есть новые работы и хотя бы две работы для сравнения.
+// Работы - подкаталоги каталогов newWorks, oldWorks: каталог с несколькими работами можно проверить без старых работ.
+// Файлы вне подкаталогов работами не считаются: они не переносятся при подготовке исходного кода
+// и не сопоставляются с работами при разборе отчёта.
+func checkWorks(newWorks []string, oldWorks []string) error {
+	if len(newWorks) == 0 {
+		return ErrNoNewWork
+	}
+
+	count := 0
+	for _, root := range append(append([]string{}, newWorks...), oldWorks...) {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				count++
+			}
+		}
+		if count > 1 {
+			return nil
+		}
+	}
+
+	return ErrNoWorks
+}
@@ -0,0 +1,98 @@
+package checker
+
+import (
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Единицы измерения позиций по номеру.
+var fuzzUnits = []OffsetUnit{UnitRunes, UnitBytes, UnitUTF16}
+
+// FuzzPosition проверяет перевод строк и столбцов Jplag в позиции:
+// перевод не завершается паникой, фрагмент находится в пределах файла
+// и начинается и заканчивается на границах символов.
+// Начальный корпус: testdata/fuzz/FuzzPosition.
+func FuzzPosition(f *testing.F) {
+	f.Fuzz(func(t *testing.T, content []byte, startLine, startCol, endLine, endCol uint64, unitIndex uint8, tabWidth uint8) {
+		unit := fuzzUnits[int(unitIndex)%len(fuzzUnits)]
+		lines := NewLineIndex(content)
+
+		start, size := lines.Position(startLine, startCol, endLine, endCol, unit, int(tabWidth))
+
+		total := lines.ToUnit(len(content), unit)
+		if start > total || size > total-start {
+			t.Fatalf("фрагмент [%d, %d+%d) за пределами файла длиной %d (%s)", start, start, size, total, unit)
+		}
+
+		// Обратный перевод позиций в байты: в пределах файла, на границах символов.
+		from, to := lines.FromUnit(start, unit), lines.FromUnit(start+size, unit)
+		if from < 0 || to < from || to > len(content) {
+			t.Fatalf("байтовые позиции [%d, %d) за пределами файла длиной %d", from, to, len(content))
+		}
+		if utf8.Valid(content) && (!utf8.RuneStart(at(content, from)) || !utf8.RuneStart(at(content, to))) {
+			t.Fatalf("байтовые позиции [%d, %d) не на границах символов", from, to)
+		}
+		if lines.ToUnit(from, unit) != start || lines.ToUnit(to, unit) != start+size {
+			t.Fatalf("позиции [%d, %d) не совпадают с обратным переводом [%d, %d)",
+				start, start+size, lines.ToUnit(from, unit), lines.ToUnit(to, unit))
+		}
+	})
+}
+
+// at возвращает байт содержимого в позиции (в конце содержимого - начало символа).
+func at(content []byte, pos int) byte {
+	if pos >= len(content) {
+		return 0
+	}
+	return content[pos]
+}
+
+// FuzzSubmission проверяет сопоставление работ и файлов из отчёта Jplag с каталогами:
+// сопоставление не завершается паникой, работа - подкаталог каталога анализа,
+// файл находится внутри каталога работы.
+// Начальный корпус: testdata/fuzz/FuzzSubmission.
+func FuzzSubmission(f *testing.F) {
+	dir := f.TempDir()
+	roots := []string{path.Join(dir, "new"), path.Join(dir, "old")}
+	for _, work := range []string{"new/101", "new/1_2", "old/202", "old/new_101"} {
+		if err := os.MkdirAll(path.Join(dir, work), os.ModePerm); err != nil {
+			f.Fatal(err)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, name string, file string, indexed bool) {
+		report := &jplagReport{
+			roots:       roots,
+			submissions: make(map[string]submission),
+			lines:       make(map[string]*LineIndex),
+		}
+		if indexed {
+			report.files = map[string]fileSet{name: {file: struct{}{}}}
+		}
+
+		sub, err := report.submission(name)
+		if err != nil {
+			return
+		}
+
+		// Работа - подкаталог одного из каталогов анализа.
+		if !slices.Contains(roots, path.Dir(sub.dir)) || path.Base(sub.dir) == ".." {
+			t.Fatalf("работа %q сопоставлена с каталогом %s вне каталогов анализа", name, sub.dir)
+		}
+
+		rel, err := report.file(sub, file)
+		if err != nil {
+			return
+		}
+
+		// Файл внутри каталога работы.
+		filePath := path.Join(sub.dir, rel)
+		if !strings.HasPrefix(filePath, sub.dir+"/") {
+			t.Fatalf("файл %q работы %q сопоставлен с путём %s вне каталога работы %s", file, name, filePath, sub.dir)
+		}
+	})
+}
@@ -0,0 +1,314 @@
+package checker
+
+import (
+	"CodeBorrowing/internal/logger"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Перезапись эталонных результатов: go test ./internal/checker -run TestJplagGolden -update
+var update = flag.Bool("update", false, "перезаписать эталонные результаты разбора отчётов Jplag")
+
+// Запись результирующих архивов случаев без отчёта report/ запуском Jplag из JPLAG_JAR для случаев
+// той же версии (старшая и младшая части) и перезапись их эталонов: JPLAG_JAR=./jplag-5.1.0.jar go test ./internal/checker -run TestJplagGolden -record
+var record = flag.Bool("record", false, "записать результирующие архивы случаев запуском Jplag из "+envTestJplagJar)
+
+// Эталонные отчёты Jplag: testdata/jplag/<случай>/
+//
+//	case.json      - описание случая и параметры анализа (goldenCase);
+//	report.zip     - результирующий архив запуска Jplag версии case.json (записывается с флагом -record);
+//	report/        - или содержимое архива, составленное вручную по схеме версии для пограничных случаев,
+//	                 которые не получить запуском Jplag (архив собирается при запуске теста);
+//	works/<root>/  - каталоги, переданные анализатору, с работами в подкаталогах
+//	                 (первый каталог - новые работы, остальные - старые);
+//	expected.json  - эталонный результат разбора (goldenReport).
+const goldenDir = "testdata/jplag"
+
+// goldenCase - описание случая.
+type goldenCase struct {
+	Description string     `json:"description"`
+	Jplag       string     `json:"jplag"` // версия Jplag отчёта.
+	Roots       []string   `json:"roots"` // каталоги из works в порядке передачи анализатору.
+	Language    string     `json:"language"`
+	OffsetUnit  OffsetUnit `json:"offset_unit"`
+	TabWidth    int        `json:"tab_width"`
+}
+
+// goldenReport - результат разбора отчёта.
+type goldenReport struct {
+	Info  RunInfo      `json:"info"`
+	Items []goldenItem `json:"items"`
+}
+
+// goldenItem - сравнение работ. Каталоги работ указываются относительно works,
+// для совпадений дополнительно указывается выделенный текст.
+type goldenItem struct {
+	Work1Dir string `json:"work1_dir"`
+	Work2Dir string `json:"work2_dir"`
+	*ReportItem
+	Fragments []goldenFragment `json:"fragments"`
+}
+
+// goldenFragment - выделенный текст совпадения в первой и второй работах.
+type goldenFragment struct {
+	Work1Text string `json:"work1_text"`
+	Work2Text string `json:"work2_text"`
+}
+
+func TestJplagGolden(t *testing.T) {
+	cases, err := os.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Версия Jplag для записи архивов.
+	var jplagJar string
+	var jplagVersion Version
+	if *record {
+		if jplagJar = os.Getenv(envTestJplagJar); jplagJar == "" {
+			t.Fatalf("для записи архивов установите переменную среды %s", envTestJplagJar)
+		}
+		capabilities, err := ProbeJplag(jplagJar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jplagVersion = capabilities.Version
+	}
+
+	for _, entry := range cases {
+		if !entry.IsDir() {
+			continue
+		}
+
+		t.Run(entry.Name(), func(t *testing.T) {
+			dir := path.Join(goldenDir, entry.Name())
+
+			// Запись архива запуском Jplag.
+			_, err := os.Stat(path.Join(dir, "report"))
+			manual := err == nil
+			recorded := false
+			if *record && !manual {
+				c := readGoldenCase(t, dir)
+				version, err := parseVersion(c.Jplag)
+				if err == nil && version.Major == jplagVersion.Major && version.Minor == jplagVersion.Minor {
+					recordGoldenCase(t, dir, c, jplagJar)
+					recorded = true
+				}
+			}
+			if _, err = os.Stat(path.Join(dir, "report.zip")); err != nil && !manual {
+				t.Skipf("архив не записан: запустите тест с флагом -record и Jplag %s", readGoldenCase(t, dir).Jplag)
+			}
+
+			actual := parseGoldenCase(t, dir)
+
+			expectedPath := path.Join(dir, "expected.json")
+			if *update || recorded {
+				if err := os.WriteFile(expectedPath, actual, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(expectedPath)
+			if err != nil {
+				t.Fatalf("%v (создайте эталон с флагом -update)", err)
+			}
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("результат разбора отличается от %s (после намеренного изменения разбора запустите тест с флагом -update):\n%s",
+					expectedPath, diffLines(string(expected), string(actual)))
+			}
+		})
+	}
+}
+
+// readGoldenCase читает описание случая.
+func readGoldenCase(t *testing.T, dir string) goldenCase {
+	t.Helper()
+
+	content, err := os.ReadFile(path.Join(dir, "case.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c goldenCase
+	if err = json.Unmarshal(content, &c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// parseGoldenCase собирает архив отчёта случая, разбирает его и возвращает результат в формате json.
+func parseGoldenCase(t *testing.T, dir string) []byte {
+	t.Helper()
+
+	c := readGoldenCase(t, dir)
+
+	// Записанный архив Jplag или архив из отчёта, составленного вручную.
+	resultPath := path.Join(dir, "report.zip")
+	if _, err := os.Stat(path.Join(dir, "report")); err == nil {
+		resultPath = path.Join(t.TempDir(), ResultFile)
+		writeGoldenZip(t, path.Join(dir, "report"), resultPath)
+	}
+
+	worksDir, err := filepath.Abs(path.Join(dir, "works"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := make([]string, len(c.Roots))
+	for i, root := range c.Roots {
+		roots[i] = path.Join(worksDir, root)
+	}
+
+	c1 := &jplag{
+		logger:  logger.NewLogger(t.TempDir()),
+		options: Options{Language: c.Language, OffsetUnit: c.OffsetUnit, TabWidth: c.TabWidth},
+	}
+	items, err := c1.parse(resultPath, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Порядок сравнений и работ, которые не удалось обработать, в отчёте не определён.
+	report := goldenReport{Info: c1.LastRunInfo()}
+	slices.SortFunc(report.Info.FailedSubmissions, func(a, b FailedSubmission) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortFunc(items, func(a, b *ReportItem) int {
+		if a.Work1Name != b.Work1Name {
+			return strings.Compare(a.Work1Name, b.Work1Name)
+		}
+		return strings.Compare(a.Work2Name, b.Work2Name)
+	})
+
+	for _, item := range items {
+		g := goldenItem{
+			Work1Dir:   relativeDir(t, worksDir, item.Work1Dir),
+			Work2Dir:   relativeDir(t, worksDir, item.Work2Dir),
+			ReportItem: item,
+			Fragments:  make([]goldenFragment, 0, len(item.Matches)),
+		}
+		for _, m := range item.Matches {
+			g.Fragments = append(g.Fragments, goldenFragment{
+				Work1Text: fragmentText(t, path.Join(item.Work1Dir, m.Work1File), m.Work1Start, m.Work1Size, c.OffsetUnit),
+				Work2Text: fragmentText(t, path.Join(item.Work2Dir, m.Work2File), m.Work2Start, m.Work2Size, c.OffsetUnit),
+			})
+		}
+		report.Items = append(report.Items, g)
+	}
+
+	result, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(result, '\n')
+}
+
+// recordGoldenCase записывает результирующий архив случая запуском Jplag из jar-архива jplagJar.
+func recordGoldenCase(t *testing.T, dir string, c goldenCase, jplagJar string) {
+	t.Helper()
+
+	roots := make([]string, len(c.Roots))
+	for i, root := range c.Roots {
+		var err error
+		if roots[i], err = filepath.Abs(path.Join(dir, "works", root)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	options := Options{Language: c.Language, OffsetUnit: c.OffsetUnit, TabWidth: c.TabWidth}
+	jplagChecker := NewJplagChecker(logger.NewLogger(t.TempDir()), jplagJar, t.TempDir(), "", 0, options).(*jplag)
+	if _, err := jplagChecker.RunCapture(roots[:1], roots[1:], path.Join(dir, "report.zip")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeGoldenZip собирает архив dst из файлов каталога src.
+func writeGoldenZip(t *testing.T, src string, dst string) {
+	t.Helper()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	writer := zip.NewWriter(f)
+	err = filepath.WalkDir(src, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		w, err := writer.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// relativeDir возвращает каталог работы относительно каталога works.
+func relativeDir(t *testing.T, worksDir string, dir string) string {
+	t.Helper()
+
+	rel, err := filepath.Rel(worksDir, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// fragmentText возвращает текст фрагмента файла.
+func fragmentText(t *testing.T, filePath string, start uint64, size uint64, unit OffsetUnit) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := NewLineIndex(content)
+	return string(content[lines.FromUnit(start, unit):lines.FromUnit(start+size, unit)])
+}
+
+// diffLines возвращает отличающиеся строки эталона и результата.
+func diffLines(expected string, actual string) string {
+	e, a := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+
+	sb := strings.Builder{}
+	for i := 0; i < max(len(e), len(a)); i++ {
+		var el, al string
+		if i < len(e) {
+			el = e[i]
+		}
+		if i < len(a) {
+			al = a[i]
+		}
+		if el != al {
+			sb.WriteString("- " + el + "\n+ " + al + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -2,16 +2,14 @@ package checker
 
 import (
 	"CodeBorrowing/internal/logger"
-	"CodeBorrowing/internal/task"
 	"CodeBorrowing/internal/utils"
 	"archive/zip"
-	"encoding/json"
-	"io"
+	"errors"
 	"os"
 	"os/exec"
 	"path"
-	"strconv"
 	"strings"
+	"time"
 )
 
 // ResultFile название результирующего файла.
@@ -20,24 +18,43 @@ const ResultFile = "result.zip"
 type jplag struct {
 	logger      *logger.Logger
 	checkerPath string
+	options     Options
 	workDir     string
-	taskService task.Service
+	lastInfo    RunInfo
+	worker      *jplagWorker
 }
 
 // NewJplagChecker создаёт адаптер для работы с Jplag.
-func NewJplagChecker(logger *logger.Logger, checkerPath string, workDir string, taskService task.Service) Checker {
-	return &jplag{
+// WorkerPath: путь к jar-архиву постоянного процесса Jplag (пустая строка - отдельный процесс на каждый анализ).
+// WorkerTimeout: время ожидания ответа постоянного процесса (0 - без ограничения), после которого
+// процесс завершается, а анализ выполняется отдельным процессом.
+// Options: параметры анализа (язык - идентификатор языка Jplag).
+func NewJplagChecker(logger *logger.Logger, checkerPath string, workDir string, workerPath string, workerTimeout time.Duration,
+	options Options) Checker {
+	c := &jplag{
 		logger:      logger,
 		checkerPath: checkerPath,
+		options:     options,
 		workDir:     workDir,
-		taskService: taskService,
 	}
+
+	if workerPath != "" {
+		c.worker = newJplagWorker(logger, checkerPath, workerPath, workerTimeout)
+	}
+
+	return c
 }
 
 // Run запускает анализ работ.
 // newWorks - путь к каталогам с новыми работами.
 // oldWorks - путь к каталогам со старыми работами.
 func (c *jplag) Run(newWorks []string, oldWorks []string) ([]*ReportItem, error) {
+	return c.RunCapture(newWorks, oldWorks, "")
+}
+
+// RunCapture запускает анализ работ и сохраняет результирующий архив Jplag в файл output
+// (пустая строка - архив не сохраняется).
+func (c *jplag) RunCapture(newWorks []string, oldWorks []string, output string) ([]*ReportItem, error) {
 	// Путь к результирующему файлу.
 	resultPath := path.Join(c.workDir, ResultFile)
 	defer os.Remove(resultPath)
@@ -47,8 +64,16 @@ func (c *jplag) Run(newWorks []string, oldWorks []string) ([]*ReportItem, error)
 		return nil, err
 	}
 
+	// Сохранение результирующего архива.
+	if output != "" {
+		if err := utils.CopyFile(resultPath, output); err != nil {
+			c.logger.Errorf("Не удалось сохранить результат анализа в %s: %v", output, err)
+		}
+	}
+
 	// Получение анализа.
-	result, err := c.parse(resultPath)
+	roots := append(append([]string{}, newWorks...), oldWorks...)
+	result, err := c.parse(resultPath, roots)
 	if err != nil {
 		return nil, err
 	}
@@ -56,13 +81,30 @@ func (c *jplag) Run(newWorks []string, oldWorks []string) ([]*ReportItem, error)
 	return result, nil
 }
 
+// Replay разбирает сохранённый результирующий архив Jplag.
+// Работы сопоставляются с каталогами newWorks, oldWorks по названиям каталогов.
+func (c *jplag) Replay(newWorks []string, oldWorks []string, output string) ([]*ReportItem, error) {
+	roots := append(append([]string{}, newWorks...), oldWorks...)
+	return c.parse(output, roots)
+}
+
+// Close завершает постоянный процесс Jplag.
+func (c *jplag) Close() error {
+	if c.worker != nil {
+		return c.worker.Close()
+	}
+	return nil
+}
+
+// LastRunInfo возвращает сведения о последнем запуске анализа.
+func (c *jplag) LastRunInfo() RunInfo {
+	return c.lastInfo
+}
+
 // Exec запускает анализ работ.
 func (c *jplag) exec(newWorks []string, oldWorks []string, resultPath string) error {
-	if len(newWorks) == 0 {
-		return ErrNoNewWork
-	}
-	if len(oldWorks) == 0 && len(newWorks) == 1 {
-		return ErrNoWorks
+	if err := checkWorks(newWorks, oldWorks); err != nil {
+		return err
 	}
 
 	// Создание рабочего каталога.
@@ -79,16 +121,27 @@ func (c *jplag) exec(newWorks []string, oldWorks []string, resultPath string) er
 		}
 	}
 
-	// Формирование команды запуска анализа.
+	// Формирование аргументов запуска анализа.
 	newWorksStr := strings.Join(newWorks, ",")
-	cmd := exec.Command("java", "-jar", c.checkerPath, "-new", newWorksStr, "-l", "csharp", "-r", resultPath)
+	args := []string{"-new", newWorksStr, "-l", c.options.Language, "-r", resultPath}
 
 	// Если имеются старые работы, добавить их в соответствующую категорию.
 	if len(oldWorks) != 0 {
 		oldWorksStr := strings.Join(oldWorks, ",")
-		cmd.Args = append(cmd.Args, "-old", oldWorksStr)
+		args = append(args, "-old", oldWorksStr)
+	}
+
+	// Анализ в постоянном процессе Jplag. Ошибка анализа повторилась бы и в отдельном процессе,
+	// отдельный процесс запускается, только если постоянный процесс не работает.
+	if c.worker != nil {
+		err := c.worker.Run(args)
+		if err == nil || errors.Is(err, ErrWorkerAnalysis) {
+			return err
+		}
+		c.logger.Errorf("Постоянный процесс Jplag: %v. Запуск отдельного процесса", err)
 	}
 
+	cmd := exec.Command("java", append([]string{"-jar", c.checkerPath}, args...)...)
 	if err := cmd.Run(); err != nil {
 		return err
 	}
@@ -97,7 +150,8 @@ func (c *jplag) exec(newWorks []string, oldWorks []string, resultPath string) er
 }
 
 // Parse читает результат анализа работ.
-func (c *jplag) parse(resultPath string) ([]*ReportItem, error) {
+// Roots: каталоги, переданные анализатору.
+func (c *jplag) parse(resultPath string, roots []string) ([]*ReportItem, error) {
 	// Открыть результирующий архив.
 	zipReader, err := zip.OpenReader(resultPath)
 	if err != nil {
@@ -105,154 +159,104 @@ func (c *jplag) parse(resultPath string) ([]*ReportItem, error) {
 	}
 	defer zipReader.Close()
 
+	// Чтение служебных файлов отчёта.
+	report, err := readReport(&zipReader.Reader, roots)
+	if err != nil {
+		return nil, err
+	}
+	c.lastInfo = report.info
+
 	var reports []*ReportItem
 
-	for _, f := range zipReader.File {
-		// Обработка элемента архива.
-		report, err := c.readItem(f)
+	for _, f := range report.comparisons {
+		// Обработка сравнения работ.
+		item, err := c.readItem(f, report)
 		if err != nil {
 			c.logger.Error(err)
 		}
 
 		// Если отчёт был создан - добавить.
-		if report != nil {
-			reports = append(reports, report)
+		if item != nil {
+			reports = append(reports, item)
 		}
 	}
 
 	return reports, nil
 }
 
-// readItem читает элемент результирующего архива.
-func (c *jplag) readItem(f *zip.File) (*ReportItem, error) {
-	// Если элемент является не интересным для чтения.
-	if strings.HasPrefix(f.Name, "files") ||
-		f.Name == "options.json" ||
-		f.Name == "overview.json" ||
-		f.Name == "README.txt" ||
-		f.Name == "submissionFileIndex.json" {
-		return nil, nil
-	}
-
-	// Открыть элемент архива.
-	rc, err := f.Open()
+// readItem читает сравнение работ из результирующего архива.
+func (c *jplag) readItem(f *zip.File, report *jplagReport) (*ReportItem, error) {
+	// Парсинг данных.
+	result, err := report.readComparison(f)
 	if err != nil {
 		return nil, err
 	}
-	defer rc.Close()
 
-	// Прочитать данные.
-	data, err := io.ReadAll(rc)
+	// Сопоставление работ с каталогами.
+	sub1, err := report.submission(result.ID1)
 	if err != nil {
 		return nil, err
 	}
-
-	// Парсинг данных.
-	var result ResultDTO
-	if err = json.Unmarshal(data, &result); err != nil {
-		return nil, err
-	}
-
-	// Формирование отчета.
-	report := &ReportItem{
-		Avg: result.Similarities.Avg,
-		Max: result.Similarities.Max,
-	}
-
-	// Идентификатор первой работы.
-	report.Work1ID, err = strconv.ParseUint(strings.Split(result.ID1, "_")[0], 10, 64)
+	sub2, err := report.submission(result.ID2)
 	if err != nil {
 		return nil, err
 	}
 
-	// Идентификатор второй работы.
-	report.Work2ID, err = strconv.ParseUint(strings.Split(result.ID2, "_")[0], 10, 64)
-	if err != nil {
-		return nil, err
+	// Формирование отчета.
+	item := &ReportItem{
+		Work1ID:    sub1.workID,
+		Work2ID:    sub2.workID,
+		Work1Name:  sub1.name,
+		Work2Name:  sub2.name,
+		Work1Dir:   sub1.dir,
+		Work2Dir:   sub2.dir,
+		Avg:        result.Similarities.Avg,
+		Max:        result.Similarities.Max,
+		OffsetUnit: c.options.OffsetUnit,
 	}
 
-	// Количество лишних байт которые содержаться в пути к файлам.
-	f1Skip, f2Skip := len(result.ID1)+1, len(result.ID2)+1
-
 	for _, matchDTO := range result.Matches {
 		// Путь к файлу первой и второй работы.
-		match := MatchItem{
-			Work1File: matchDTO.File1[f1Skip:],
-			Work2File: matchDTO.File2[f2Skip:],
+		var match MatchItem
+		if match.Work1File, err = report.file(sub1, matchDTO.File1); err != nil {
+			c.logger.Error(err)
+			continue
+		}
+		if match.Work2File, err = report.file(sub2, matchDTO.File2); err != nil {
+			c.logger.Error(err)
+			continue
+		}
+
+		// Jplag 4.x не указывает столбцы - совпадение занимает строки целиком.
+		if report.format < 5 {
+			matchDTO.Start1Col, matchDTO.End1Col = 1, 0
+			matchDTO.Start2Col, matchDTO.End2Col = 1, 0
 		}
 
 		// Путь к файлам на сервере.
-		work1Path := path.Join(c.taskService.GetWorkPath(report.Work1ID), matchDTO.File1[f1Skip/2:])
-		work2Path := path.Join(c.taskService.GetWorkPath(report.Work2ID), matchDTO.File2[f2Skip/2:])
+		work1Path := path.Join(sub1.dir, match.Work1File)
+		work2Path := path.Join(sub2.dir, match.Work2File)
 
-		// Вычисление позиций в первой работе, в которой замечена схожесть.
-		match.Work1Start, match.Work1Size, err = getPositions(work1Path, matchDTO.Start1, matchDTO.Start1Col, matchDTO.End1, matchDTO.End1Col)
+		// Индексы строк файлов.
+		lines1, err := report.lineIndex(work1Path)
 		if err != nil {
 			c.logger.Error(err)
+			continue
 		}
-
-		// Вычисление позиций во второй работе, в которой замечена схожесть.
-		match.Work2Start, match.Work2Size, err = getPositions(work2Path, matchDTO.Start2, matchDTO.Start2Col, matchDTO.End2, matchDTO.End2Col)
+		lines2, err := report.lineIndex(work2Path)
 		if err != nil {
 			c.logger.Error(err)
+			continue
 		}
 
-		report.Matches = append(report.Matches, match)
-	}
-
-	return report, nil
-}
-
-// getPositions вычисляет позиции в которых замечена схожесть.
-// Path: путь к файлу.
-// startLine: номер строки, начиная с которой замечена схожесть.
-// startCol: номер столбца, начиная с которого замечена схожесть.
-// endLine: номер строки, заканчивая с которой замечена схожесть.
-// endCol: номер столбца, заканчивая с которого замечена схожесть.
-func getPositions(path string, startLine, startCol, endLine, endCol uint64) (uint64, uint64, error) {
-	// Открытие файла.
-	f, err := os.Open(path)
-	if err != nil {
-		return 0, 0, err
-	}
-	defer f.Close()
-
-	// Чтение файла.
-	content, err := io.ReadAll(f)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	runes := []rune(string(content))
-	length := uint64(len(runes))
-	idx := uint64(0)
-
-	// Счётчики номеров строк.
-	n, m := startLine, endLine
-
-	// Цикл для пропуска строк, номер которых меньше startLine.
-	for ; idx < length && n > 1; idx++ {
-		if runes[idx] == '\n' {
-			n--
-			m--
-		}
-	}
-	if idx == length {
-		return 0, 0, nil
-	}
-
-	// Позиция, начиная с которой замечена схожесть.
-	start := idx + startCol
-	if startLine != 1 {
-		start--
-	}
+		// Вычисление позиций в первой и второй работах, в которых замечена схожесть.
+		match.Work1Start, match.Work1Size = lines1.Position(matchDTO.Start1, matchDTO.Start1Col,
+			matchDTO.End1, matchDTO.End1Col, c.options.OffsetUnit, c.options.TabWidth)
+		match.Work2Start, match.Work2Size = lines2.Position(matchDTO.Start2, matchDTO.Start2Col,
+			matchDTO.End2, matchDTO.End2Col, c.options.OffsetUnit, c.options.TabWidth)
 
-	// Цикл для пропуска строк, номер которых меньше endLine.
-	for idx = start; idx < length && m > 1; idx++ {
-		if runes[idx] == '\n' {
-			m--
-		}
+		item.Matches = append(item.Matches, match)
 	}
 
-	return start, idx - start + endCol, nil
+	return item, nil
 }
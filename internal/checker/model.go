@@ -1,23 +1,62 @@
 package checker
 
+import "encoding/json"
+
 type ReportItem struct {
 	Work1ID uint64 `json:"work1_id"`
 	Work2ID uint64 `json:"work2_id"`
 
+	// Названия работ в анализаторе.
+	Work1Name string `json:"work1_name"`
+	Work2Name string `json:"work2_name"`
+
+	// Каталоги работ на диске.
+	Work1Dir string `json:"-"`
+	Work2Dir string `json:"-"`
+
+	// События, к которым относятся работы (для работ из архива - событие архива).
+	Work1EventID uint64 `json:"work1_event_id"`
+	Work2EventID uint64 `json:"work2_event_id"`
+
 	Avg float64 `json:"avg"`
 	Max float64 `json:"max"`
 
+	// Единица измерения позиций фрагментов.
+	OffsetUnit OffsetUnit `json:"offset_unit"`
+
 	Matches []MatchItem `json:"matches"`
 }
 
 type MatchItem struct {
-	Work1File  string `json:"work1_file"`
-	Work1Start uint64 `json:"work1_start"`
-	Work1Size  uint64 `json:"work1_size"`
+	Work1File  string  `json:"work1_file"`
+	Work1Start uint64  `json:"work1_start"`
+	Work1Size  uint64  `json:"work1_size"`
+	Work1Cell  *uint32 `json:"work1_cell,omitempty"` // ячейка блокнота Jupyter (позиция указана внутри ячейки).
+
+	Work2File  string  `json:"work2_file"`
+	Work2Start uint64  `json:"work2_start"`
+	Work2Size  uint64  `json:"work2_size"`
+	Work2Cell  *uint32 `json:"work2_cell,omitempty"` // ячейка блокнота Jupyter (позиция указана внутри ячейки).
+}
+
+type RunInfo struct {
+	CheckerVersion    Version            `json:"checker_version"`
+	Language          string             `json:"language"`
+	Date              string             `json:"date"`
+	ExecutionTime     int64              `json:"execution_time"`
+	TotalComparisons  uint64             `json:"total_comparisons"`
+	FailedSubmissions []FailedSubmission `json:"failed_submissions"`
+}
+
+type FailedSubmission struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
 
-	Work2File  string `json:"work2_file"`
-	Work2Start uint64 `json:"work2_start"`
-	Work2Size  uint64 `json:"work2_size"`
+type Version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
 }
 
 type ResultDTO struct {
@@ -50,3 +89,69 @@ type MatchDTO struct {
 	End1Col uint64 `json:"end1_col"`
 	End2Col uint64 `json:"end2_col"`
 }
+
+// Формат отчёта Jplag 4.x - 5.x.
+
+type OverviewDTO struct {
+	JplagVersion                      Version                      `json:"jplag_version"`
+	Language                          json.RawMessage              `json:"language"`
+	SubmissionIDsToComparisonFileName map[string]map[string]string `json:"submission_ids_to_comparison_file_name"`
+	FailedSubmissionNames             json.RawMessage              `json:"failed_submission_names"`
+	DateOfExecution                   string                       `json:"date_of_execution"`
+	ExecutionTime                     int64                        `json:"execution_time"`
+	TotalComparisons                  uint64                       `json:"total_comparisons"`
+}
+
+type SubmissionFileIndexDTO struct {
+	SubmissionFileIndexes map[string]json.RawMessage `json:"submission_file_indexes"`
+}
+
+// Формат отчёта Jplag 6.x.
+
+type RunInformationDTO struct {
+	JplagVersion        *Version `json:"jplagVersion"`
+	ReportViewerVersion Version  `json:"reportViewerVersion"`
+	FailedSubmissions   []struct {
+		SubmissionID    string `json:"submissionId"`
+		SubmissionState string `json:"submissionState"`
+	} `json:"failedSubmissions"`
+	DateOfExecution  string `json:"dateOfExecution"`
+	SubmissionDate   string `json:"submissionDate"`
+	ExecutionTime    int64  `json:"executionTime"`
+	TotalComparisons uint64 `json:"totalComparisons"`
+}
+
+type SubmissionMappingsDTO struct {
+	SubmissionIDsToComparisonFileName map[string]map[string]string `json:"submissionIdsToComparisonFileName"`
+}
+
+type FileIndexesDTO struct {
+	FileIndexes map[string]json.RawMessage `json:"fileIndexes"`
+}
+
+type OptionsDTO struct {
+	Language json.RawMessage `json:"language"`
+}
+
+type ResultV6DTO struct {
+	FirstSubmissionID  string        `json:"firstSubmissionId"`
+	SecondSubmissionID string        `json:"secondSubmissionId"`
+	Similarities       SimilarityDTO `json:"similarities"`
+	Matches            []MatchV6DTO  `json:"matches"`
+	FirstSimilarity    float64       `json:"firstSimilarity"`
+	SecondSimilarity   float64       `json:"secondSimilarity"`
+}
+
+type MatchV6DTO struct {
+	FirstFileName  string          `json:"firstFileName"`
+	SecondFileName string          `json:"secondFileName"`
+	StartInFirst   CodePositionDTO `json:"startInFirst"`
+	EndInFirst     CodePositionDTO `json:"endInFirst"`
+	StartInSecond  CodePositionDTO `json:"startInSecond"`
+	EndInSecond    CodePositionDTO `json:"endInSecond"`
+}
+
+type CodePositionDTO struct {
+	Line   uint64 `json:"line"`
+	Column uint64 `json:"column"`
+}
@@ -0,0 +1,293 @@
+package checker
+
+import (
+	"CodeBorrowing/internal/logger"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PluginProtocolVersion версия протокола внешнего анализатора.
+const PluginProtocolVersion = 1
+
+// plugin - внешний анализатор: исполняемый файл, работающий по протоколу JSON через stdin/stdout.
+//
+// Раннер запускает файл и передаёт в stdin PluginRequest. Каждый каталог из new/old,
+// как и в Jplag, содержит работы в подкаталогах. Анализатор сравнивает новые работы
+// между собой и со старыми работами и выводит в stdout PluginResponse.
+// Пути к файлам в совпадениях указываются относительно каталога работы,
+// позиции - в единицах options.offset_unit от начала файла.
+// Ненулевой код завершения или непустое поле error считаются ошибкой анализа.
+// Сравнения с работами вне каталогов new/old, файлами вне каталога работы
+// или фрагментами за пределами файла пропускаются.
+type plugin struct {
+	logger   *logger.Logger
+	execPath string
+	options  Options
+	lastInfo RunInfo
+}
+
+type PluginRequest struct {
+	Version int           `json:"version"`
+	New     []string      `json:"new"`
+	Old     []string      `json:"old"`
+	Options PluginOptions `json:"options"`
+}
+
+type PluginOptions struct {
+	Language   string     `json:"language"`
+	OffsetUnit OffsetUnit `json:"offset_unit"`
+}
+
+type PluginResponse struct {
+	Version int          `json:"version"`
+	Pairs   []PluginPair `json:"pairs"`
+	Info    RunInfo      `json:"info"`
+	Error   string       `json:"error"`
+}
+
+type PluginPair struct {
+	Work1   PluginWork  `json:"work1"`
+	Work2   PluginWork  `json:"work2"`
+	Avg     float64     `json:"avg"`
+	Max     float64     `json:"max"`
+	Matches []MatchItem `json:"matches"`
+}
+
+type PluginWork struct {
+	Root string `json:"root"` // каталог из new или old.
+	Name string `json:"name"` // название подкаталога работы.
+}
+
+// pluginCapture - сохранённые запрос и ответ анализатора.
+type pluginCapture struct {
+	Request PluginRequest `json:"request"`
+	Stdout  string        `json:"stdout"`
+	Stderr  string        `json:"stderr"`
+}
+
+// NewPluginChecker создаёт адаптер для внешнего анализатора.
+// ExecPath: путь к исполняемому файлу анализатора.
+// Options: параметры анализа.
+func NewPluginChecker(logger *logger.Logger, execPath string, options Options) Checker {
+	return &plugin{
+		logger:   logger,
+		execPath: execPath,
+		options:  options,
+	}
+}
+
+// Run запускает анализ работ.
+// newWorks - путь к каталогам с новыми работами.
+// oldWorks - путь к каталогам со старыми работами.
+func (c *plugin) Run(newWorks []string, oldWorks []string) ([]*ReportItem, error) {
+	return c.RunCapture(newWorks, oldWorks, "")
+}
+
+// RunCapture запускает анализ работ и сохраняет запрос и исходный ответ анализатора в файл output
+// (пустая строка - ответ не сохраняется).
+func (c *plugin) RunCapture(newWorks []string, oldWorks []string, output string) ([]*ReportItem, error) {
+	if err := checkWorks(newWorks, oldWorks); err != nil {
+		return nil, err
+	}
+
+	// Формирование запроса.
+	pluginRequest := PluginRequest{
+		Version: PluginProtocolVersion,
+		New:     newWorks,
+		Old:     oldWorks,
+		Options: PluginOptions{Language: c.options.Language, OffsetUnit: c.options.OffsetUnit},
+	}
+	request, err := json.Marshal(pluginRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Запуск анализатора.
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(c.execPath)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	// Сохранение запроса и ответа, в том числе при ошибке анализатора.
+	if output != "" {
+		captured, err := json.MarshalIndent(pluginCapture{
+			Request: pluginRequest,
+			Stdout:  stdout.String(),
+			Stderr:  stderr.String(),
+		}, "", "  ")
+		if err == nil {
+			err = os.WriteFile(output, captured, 0644)
+		}
+		if err != nil {
+			c.logger.Errorf("Не удалось сохранить результат анализа в %s: %v", output, err)
+		}
+	}
+
+	if runErr != nil {
+		return nil, fmt.Errorf("%s: %v: %s", c.execPath, runErr, stderr.String())
+	}
+
+	return c.parse(stdout.Bytes(), append(append([]string{}, newWorks...), oldWorks...), nil)
+}
+
+// Replay разбирает сохранённый ответ анализатора.
+// Каталоги работ из ответа заменяются на каталоги newWorks, oldWorks с тем же порядковым номером в запросе.
+func (c *plugin) Replay(newWorks []string, oldWorks []string, output string) ([]*ReportItem, error) {
+	content, err := os.ReadFile(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var captured pluginCapture
+	if err = json.Unmarshal(content, &captured); err != nil {
+		return nil, fmt.Errorf("%s: %v", output, err)
+	}
+
+	requestRoots := append(append([]string{}, captured.Request.New...), captured.Request.Old...)
+	roots := append(append([]string{}, newWorks...), oldWorks...)
+	if len(requestRoots) != len(roots) {
+		return nil, fmt.Errorf("%s: в запросе %d каталогов работ, передано %d", output, len(requestRoots), len(roots))
+	}
+
+	rename := make(map[string]string, len(roots))
+	for i, root := range requestRoots {
+		rename[root] = roots[i]
+	}
+
+	return c.parse([]byte(captured.Stdout), roots, rename)
+}
+
+// parse читает ответ анализатора.
+// Roots: каталоги, переданные анализатору.
+// Rename: замена каталогов работ из ответа (nil - без замены).
+func (c *plugin) parse(stdout []byte, roots []string, rename map[string]string) ([]*ReportItem, error) {
+	// Чтение ответа.
+	var response PluginResponse
+	if err := json.Unmarshal(stdout, &response); err != nil {
+		return nil, fmt.Errorf("%s: некорректный ответ: %v", c.execPath, err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("%s: %s", c.execPath, response.Error)
+	}
+	if response.Version != PluginProtocolVersion {
+		return nil, fmt.Errorf("%w: протокол %d", ErrUnsupportedVersion, response.Version)
+	}
+	c.lastInfo = response.Info
+
+	// Формирование отчётов.
+	result := make([]*ReportItem, 0, len(response.Pairs))
+	sizes := make(map[string]uint64)
+	for _, pair := range response.Pairs {
+		if root, ok := rename[pair.Work1.Root]; ok {
+			pair.Work1.Root = root
+		}
+		if root, ok := rename[pair.Work2.Root]; ok {
+			pair.Work2.Root = root
+		}
+
+		item, err := c.readPair(pair, roots, sizes)
+		if err != nil {
+			c.logger.Errorf("%s: сравнение %s и %s пропущено: %v", c.execPath, pair.Work1.Name, pair.Work2.Name, err)
+			continue
+		}
+
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// readPair проверяет сравнение работ из ответа анализатора и формирует отчёт.
+// Sizes: размеры прочитанных файлов в единицах позиций.
+func (c *plugin) readPair(pair PluginPair, roots []string, sizes map[string]uint64) (*ReportItem, error) {
+	work1Dir, err := pluginWorkDir(pair.Work1, roots)
+	if err != nil {
+		return nil, err
+	}
+	work2Dir, err := pluginWorkDir(pair.Work2, roots)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &ReportItem{
+		Work1Name:  pair.Work1.Name,
+		Work2Name:  pair.Work2.Name,
+		Work1Dir:   work1Dir,
+		Work2Dir:   work2Dir,
+		Avg:        pair.Avg,
+		Max:        pair.Max,
+		OffsetUnit: c.options.OffsetUnit,
+		Matches:    make([]MatchItem, 0, len(pair.Matches)),
+	}
+	item.Work1ID, _ = strconv.ParseUint(pair.Work1.Name, 10, 64)
+	item.Work2ID, _ = strconv.ParseUint(pair.Work2.Name, 10, 64)
+
+	for _, match := range pair.Matches {
+		if match.Work1File, err = c.checkFragment(work1Dir, match.Work1File, match.Work1Start, match.Work1Size, sizes); err != nil {
+			return nil, err
+		}
+		if match.Work2File, err = c.checkFragment(work2Dir, match.Work2File, match.Work2Start, match.Work2Size, sizes); err != nil {
+			return nil, err
+		}
+		item.Matches = append(item.Matches, match)
+	}
+
+	return item, nil
+}
+
+// pluginWorkDir возвращает каталог работы. Работа должна быть подкаталогом одного из каталогов анализа.
+func pluginWorkDir(work PluginWork, roots []string) (string, error) {
+	if !slices.Contains(roots, work.Root) {
+		return "", fmt.Errorf("каталог %s не передавался анализатору", work.Root)
+	}
+	if work.Name == "" || work.Name == "." || work.Name == ".." || strings.ContainsAny(work.Name, `/\`) {
+		return "", fmt.Errorf("некорректное название работы %q", work.Name)
+	}
+
+	dir := path.Join(work.Root, work.Name)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("работа %s не найдена в каталоге %s", work.Name, work.Root)
+	}
+
+	return dir, nil
+}
+
+// checkFragment проверяет, что файл находится внутри каталога работы, а фрагмент - в пределах файла.
+// Возвращает путь к файлу относительно каталога работы.
+func (c *plugin) checkFragment(workDir string, name string, start uint64, size uint64, sizes map[string]uint64) (string, error) {
+	file := path.Clean(name)
+	if file == "." || file == ".." || strings.HasPrefix(file, "../") || path.IsAbs(file) || strings.Contains(file, `\`) {
+		return "", fmt.Errorf("файл %q не относится к работе %s", name, path.Base(workDir))
+	}
+
+	filePath := path.Join(workDir, file)
+	fileSize, ok := sizes[filePath]
+	if !ok {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		fileSize = NewLineIndex(content).ToUnit(len(content), c.options.OffsetUnit)
+		sizes[filePath] = fileSize
+	}
+
+	if start > fileSize || size > fileSize-start {
+		return "", fmt.Errorf("фрагмент %d+%d за пределами файла %s (%d)", start, size, filePath, fileSize)
+	}
+
+	return file, nil
+}
+
+// LastRunInfo возвращает сведения о последнем запуске анализа.
+func (c *plugin) LastRunInfo() RunInfo {
+	return c.lastInfo
+}
@@ -0,0 +1,226 @@
+package checker
+
+import (
+	"CodeBorrowing/internal/logger"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pluginRoots создаёт каталоги новых и старых работ и каталог, не передаваемый анализатору.
+func pluginRoots(t *testing.T) (newRoot string, oldRoot string, outside string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	newRoot, oldRoot, outside = path.Join(dir, "new"), path.Join(dir, "old"), path.Join(dir, "outside")
+	files := map[string]string{
+		path.Join(newRoot, "101", "Main.java"):    "// Проверка\nclass Main {}\n",
+		path.Join(newRoot, "102", "Main.java"):    "class Main {}\n",
+		path.Join(oldRoot, "201", "Main.java"):    "class Main {}\n",
+		path.Join(outside, "301", "Main.java"):    "class Main {}\n",
+		path.Join(newRoot, "101", "src/App.java"): "class App {}\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(path.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return newRoot, oldRoot, outside
+}
+
+// newTestPlugin создаёт анализатор - сценарий, сохраняющий запрос в request.json
+// и выводящий response с кодом завершения code.
+func newTestPlugin(t *testing.T, response any, code int) (Checker, string) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("сценарий анализатора требует sh")
+	}
+
+	dir := t.TempDir()
+	content, ok := response.(string)
+	if !ok {
+		data, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content = string(data)
+	}
+	if err := os.WriteFile(path.Join(dir, "response.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	script := "#!/bin/sh\ncat > '" + path.Join(dir, "request.json") + "'\ncat '" + path.Join(dir, "response.json") +
+		"'\necho 'сообщение анализатора' >&2\nexit " + strconv.Itoa(code) + "\n"
+	execPath := path.Join(dir, "plugin")
+	if err := os.WriteFile(execPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	options := Options{Language: "java", OffsetUnit: UnitRunes}
+	return NewPluginChecker(logger.NewLogger(t.TempDir()), execPath, options), path.Join(dir, "request.json")
+}
+
+func TestPluginRun(t *testing.T) {
+	newRoot, oldRoot, _ := pluginRoots(t)
+
+	c, requestPath := newTestPlugin(t, PluginResponse{
+		Version: PluginProtocolVersion,
+		Info:    RunInfo{Language: "java"},
+		Pairs: []PluginPair{{
+			Work1: PluginWork{Root: newRoot, Name: "101"},
+			Work2: PluginWork{Root: oldRoot, Name: "201"},
+			Avg:   0.5,
+			Max:   0.75,
+			Matches: []MatchItem{
+				// Фрагмент до конца файла в символах Unicode.
+				{Work1File: "Main.java", Work1Start: 12, Work1Size: 14, Work2File: "./Main.java", Work2Start: 0, Work2Size: 14},
+				{Work1File: "src/App.java", Work1Start: 0, Work1Size: 5, Work2File: "Main.java", Work2Start: 0, Work2Size: 5},
+			},
+		}},
+	}, 0)
+
+	items, err := c.Run([]string{newRoot}, []string{oldRoot})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Запрос анализатору.
+	content, err := os.ReadFile(requestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request PluginRequest
+	if err = json.Unmarshal(content, &request); err != nil {
+		t.Fatal(err)
+	}
+	if request.Version != PluginProtocolVersion || len(request.New) != 1 || request.New[0] != newRoot ||
+		len(request.Old) != 1 || request.Old[0] != oldRoot || request.Options.Language != "java" || request.Options.OffsetUnit != UnitRunes {
+		t.Errorf("запрос %+v", request)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("сравнений %d, ожидалось 1", len(items))
+	}
+	item := items[0]
+	if item.Work1ID != 101 || item.Work2ID != 201 || item.Work1Dir != path.Join(newRoot, "101") ||
+		item.Work2Dir != path.Join(oldRoot, "201") || item.Avg != 0.5 || item.Max != 0.75 || item.OffsetUnit != UnitRunes {
+		t.Errorf("сравнение %+v", item)
+	}
+	if len(item.Matches) != 2 || item.Matches[0].Work2File != "Main.java" || item.Matches[1].Work1File != "src/App.java" {
+		t.Errorf("совпадения %+v", item.Matches)
+	}
+	if info := c.(RunInfoProvider).LastRunInfo(); info.Language != "java" {
+		t.Errorf("сведения о запуске %+v", info)
+	}
+}
+
+func TestPluginRunError(t *testing.T) {
+	newRoot, oldRoot, _ := pluginRoots(t)
+
+	tests := []struct {
+		name     string
+		response any
+		code     int
+		expected string
+		err      error
+	}{
+		{
+			name:     "поле error",
+			response: PluginResponse{Version: PluginProtocolVersion, Error: "язык не поддерживается"},
+			expected: "язык не поддерживается",
+		},
+		{
+			name:     "версия протокола",
+			response: PluginResponse{Version: PluginProtocolVersion + 1},
+			err:      ErrUnsupportedVersion,
+		},
+		{
+			name:     "код завершения",
+			response: PluginResponse{Version: PluginProtocolVersion},
+			code:     2,
+			expected: "сообщение анализатора",
+		},
+		{
+			name:     "некорректный ответ",
+			response: "не json",
+			expected: "некорректный ответ",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := newTestPlugin(t, test.response, test.code)
+
+			items, err := c.Run([]string{newRoot}, []string{oldRoot})
+			if err == nil {
+				t.Fatalf("ошибка не получена, сравнения %+v", items)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Errorf("ошибка %v, ожидалась %v", err, test.err)
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("ошибка %v, ожидалось сообщение %q", err, test.expected)
+			}
+		})
+	}
+}
+
+// TestPluginRunInvalid проверяет, что сравнения с работами и файлами вне каталогов анализа
+// и фрагментами за пределами файлов пропускаются.
+func TestPluginRunInvalid(t *testing.T) {
+	newRoot, oldRoot, outside := pluginRoots(t)
+
+	valid := MatchItem{Work1File: "Main.java", Work1Start: 0, Work1Size: 5, Work2File: "Main.java", Work2Start: 0, Work2Size: 5}
+	pair := func(work1 PluginWork, match MatchItem) PluginPair {
+		return PluginPair{Work1: work1, Work2: PluginWork{Root: newRoot, Name: "102"}, Matches: []MatchItem{valid, match}}
+	}
+	withFile := func(file string) MatchItem {
+		m := valid
+		m.Work1File = file
+		return m
+	}
+	withStart := func(start uint64, size uint64) MatchItem {
+		m := valid
+		m.Work1Start, m.Work1Size = start, size
+		return m
+	}
+	work := PluginWork{Root: newRoot, Name: "101"}
+
+	c, _ := newTestPlugin(t, PluginResponse{
+		Version: PluginProtocolVersion,
+		Pairs: []PluginPair{
+			pair(PluginWork{Root: outside, Name: "301"}, valid),        // каталог не передавался анализатору.
+			pair(PluginWork{Root: newRoot + "/", Name: "101"}, valid),  // каталог в другой записи.
+			pair(PluginWork{Root: oldRoot, Name: "../new/101"}, valid), // работа вне каталога.
+			pair(PluginWork{Root: newRoot, Name: ".."}, valid),
+			pair(PluginWork{Root: newRoot, Name: "103"}, valid), // работа отсутствует.
+			pair(work, withFile("../102/Main.java")),
+			pair(work, withFile("src/../../102/Main.java")),
+			pair(work, withFile(path.Join(newRoot, "101", "Main.java"))),
+			pair(work, withFile(`src\App.java`)),
+			pair(work, withFile("")),
+			pair(work, withFile("Missing.java")),
+			pair(work, withStart(26, 1)), // за концом файла из 26 символов.
+			pair(work, withStart(27, 0)),
+			pair(work, withStart(1, ^uint64(0))), // переполнение.
+			pair(work, withStart(26, 0)),         // пустой фрагмент в конце файла.
+		},
+	}, 0)
+
+	items, err := c.Run([]string{newRoot}, []string{oldRoot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Work1Dir != path.Join(newRoot, "101") || len(items[0].Matches) != 2 {
+		t.Errorf("сравнения %+v, ожидалось одно сравнение с пустым фрагментом в конце файла", items)
+	}
+}
@@ -0,0 +1,231 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// OffsetUnit - единица измерения позиций фрагментов в отчёте.
+type OffsetUnit string
+
+const (
+	UnitRunes OffsetUnit = "runes" // символы Unicode (code points).
+	UnitBytes OffsetUnit = "bytes" // байты UTF-8.
+	UnitUTF16 OffsetUnit = "utf16" // единицы кодировки UTF-16 (как в JavaScript).
+)
+
+// ParseOffsetUnit читает единицу измерения позиций. Пустая строка - символы Unicode.
+func ParseOffsetUnit(s string) (OffsetUnit, error) {
+	switch unit := OffsetUnit(s); unit {
+	case "":
+		return UnitRunes, nil
+	case UnitRunes, UnitBytes, UnitUTF16:
+		return unit, nil
+	default:
+		return "", fmt.Errorf("неизвестная единица измерения позиций \"%s\"", s)
+	}
+}
+
+// LineIndex - индекс строк файла для перевода строк и столбцов в позиции.
+// Строки разделяются '\n', завершающий '\r' к содержимому строки не относится.
+type LineIndex struct {
+	content []byte
+	starts  []int                   // байтовые позиции начала строк.
+	units   map[OffsetUnit][]uint64 // позиции начала строк в единицах измерения.
+}
+
+// NewLineIndex строит индекс строк содержимого файла.
+func NewLineIndex(content []byte) *LineIndex {
+	starts := []int{0}
+	for i, b := range content {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	return &LineIndex{
+		content: content,
+		starts:  starts,
+		units:   make(map[OffsetUnit][]uint64),
+	}
+}
+
+// Content возвращает содержимое файла.
+func (l *LineIndex) Content() []byte {
+	return l.content
+}
+
+// Lines возвращает количество строк.
+func (l *LineIndex) Lines() int {
+	return len(l.starts)
+}
+
+// Position переводит фрагмент, заданный строками и столбцами Jplag, в позицию и длину в единицах unit.
+// Строки и столбцы нумеруются с 1, конечный столбец входит во фрагмент.
+// Столбец 0 означает начало строки для начала фрагмента и конец строки для конца фрагмента.
+// TabWidth: ширина табуляции в столбцах (1 - табуляция считается одним символом).
+// Позиции за пределами файла ограничиваются его границами.
+func (l *LineIndex) Position(startLine, startCol, endLine, endCol uint64, unit OffsetUnit, tabWidth int) (uint64, uint64) {
+	start := l.column(l.line(startLine), startCol, tabWidth, false)
+	end := l.column(l.line(endLine), endCol, tabWidth, true)
+	if end < start {
+		end = start
+	}
+
+	startUnit := l.ToUnit(start, unit)
+	return startUnit, l.ToUnit(end, unit) - startUnit
+}
+
+// ToUnit переводит байтовую позицию в единицы unit.
+func (l *LineIndex) ToUnit(offset int, unit OffsetUnit) uint64 {
+	offset = min(max(offset, 0), len(l.content))
+	if unit == UnitBytes {
+		return uint64(offset)
+	}
+
+	line := l.lineOf(offset)
+	return l.lineUnits(unit)[line] + countUnits(l.content[l.starts[line]:offset], unit)
+}
+
+// FromUnit переводит позицию в единицах unit в байтовую позицию.
+func (l *LineIndex) FromUnit(offset uint64, unit OffsetUnit) int {
+	if unit == UnitBytes {
+		return int(min(offset, uint64(len(l.content))))
+	}
+
+	// Поиск строки, содержащей позицию.
+	units := l.lineUnits(unit)
+	line := sort.Search(len(units), func(i int) bool { return units[i] > offset }) - 1
+
+	// Поиск позиции внутри строки.
+	pos, rest := l.starts[line], offset-units[line]
+	for pos < len(l.content) && rest > 0 {
+		r, size := utf8.DecodeRune(l.content[pos:])
+		n := runeUnits(r, size, unit)
+		if n > rest {
+			break
+		}
+		rest -= n
+		pos += size
+	}
+
+	return pos
+}
+
+// LineOf возвращает номер строки (с 1), содержащей байтовую позицию.
+func (l *LineIndex) LineOf(offset int) int {
+	return l.lineOf(min(max(offset, 0), len(l.content))) + 1
+}
+
+// LineStart возвращает байтовую позицию начала строки (с 1).
+func (l *LineIndex) LineStart(line int) int {
+	return l.starts[l.line(uint64(max(line, 0)))]
+}
+
+// line переводит номер строки Jplag в индекс строки с ограничением границами файла.
+func (l *LineIndex) line(n uint64) int {
+	if n < 1 {
+		return 0
+	}
+	return int(min(n-1, uint64(len(l.starts)-1)))
+}
+
+// lineOf возвращает индекс строки, содержащей байтовую позицию.
+func (l *LineIndex) lineOf(offset int) int {
+	return sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset }) - 1
+}
+
+// lineEnd возвращает байтовую позицию конца строки (без "\r\n").
+func (l *LineIndex) lineEnd(line int) int {
+	end := len(l.content)
+	if line+1 < len(l.starts) {
+		end = l.starts[line+1] - 1
+	}
+	if end > l.starts[line] && l.content[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
+// column переводит столбец в байтовую позицию символа в строке.
+// After: позиция после символа (для конца фрагмента).
+func (l *LineIndex) column(line int, col uint64, tabWidth int, after bool) int {
+	pos, end := l.starts[line], l.lineEnd(line)
+	if col == 0 {
+		if after {
+			return end
+		}
+		return pos
+	}
+
+	// Символ занимает столбцы [c, next).
+	for c := uint64(1); pos < end; {
+		r, size := utf8.DecodeRune(l.content[pos:end])
+		next := c + 1
+		if r == '\t' && tabWidth > 1 {
+			next = ((c-1)/uint64(tabWidth)+1)*uint64(tabWidth) + 1
+		}
+
+		if col < next {
+			if after {
+				return pos + size
+			}
+			return pos
+		}
+
+		c = next
+		pos += size
+	}
+
+	return end
+}
+
+// lineUnits возвращает позиции начала строк в единицах unit.
+func (l *LineIndex) lineUnits(unit OffsetUnit) []uint64 {
+	if units, ok := l.units[unit]; ok {
+		return units
+	}
+
+	units := make([]uint64, len(l.starts))
+	for i := 1; i < len(l.starts); i++ {
+		units[i] = units[i-1] + countUnits(l.content[l.starts[i-1]:l.starts[i]], unit)
+	}
+
+	l.units[unit] = units
+	return units
+}
+
+// countUnits считает длину фрагмента в единицах unit.
+func countUnits(content []byte, unit OffsetUnit) uint64 {
+	switch unit {
+	case UnitBytes:
+		return uint64(len(content))
+	case UnitUTF16:
+		var n uint64
+		for pos := 0; pos < len(content); {
+			r, size := utf8.DecodeRune(content[pos:])
+			n += runeUnits(r, size, unit)
+			pos += size
+		}
+		return n
+	default:
+		return uint64(utf8.RuneCount(content))
+	}
+}
+
+// runeUnits возвращает длину символа в единицах unit.
+func runeUnits(r rune, size int, unit OffsetUnit) uint64 {
+	switch unit {
+	case UnitBytes:
+		return uint64(size)
+	case UnitUTF16:
+		if n := utf16.RuneLen(r); n > 0 {
+			return uint64(n)
+		}
+		return 1
+	default:
+		return 1
+	}
+}
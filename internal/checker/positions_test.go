@@ -0,0 +1,91 @@
+package checker
+
+import (
+	"testing"
+)
+
+func TestPosition(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		content                              string
+		startLine, startCol, endLine, endCol uint64
+		unit                                 OffsetUnit
+		tabWidth                             int
+		start, size                          uint64
+	}{
+		{name: "первая строка", content: "abc\ndef\n", startLine: 1, startCol: 1, endLine: 1, endCol: 1, unit: UnitRunes, tabWidth: 1, start: 0, size: 1},
+		{name: "строка целиком", content: "abc\ndef\n", startLine: 2, endLine: 2, unit: UnitRunes, tabWidth: 1, start: 4, size: 3},
+		{name: "CRLF без \\r", content: "ab\r\ncd\r\n", startLine: 1, endLine: 1, unit: UnitRunes, tabWidth: 1, start: 0, size: 2},
+		{name: "CRLF вторая строка", content: "ab\r\ncd\r\n", startLine: 2, startCol: 1, endLine: 2, endCol: 2, unit: UnitBytes, tabWidth: 1, start: 4, size: 2},
+		{name: "табуляция одним столбцом", content: "\tx = 1\n", startLine: 1, startCol: 2, endLine: 1, endCol: 2, unit: UnitRunes, tabWidth: 1, start: 1, size: 1},
+		{name: "табуляция шириной 4", content: "\tx = 1\n", startLine: 1, startCol: 5, endLine: 1, endCol: 5, unit: UnitRunes, tabWidth: 4, start: 1, size: 1},
+		{name: "столбец внутри табуляции", content: "a\tb\n", startLine: 1, startCol: 3, endLine: 1, endCol: 4, unit: UnitRunes, tabWidth: 4, start: 1, size: 1},
+		{name: "после табуляции шириной 4", content: "a\tb\n", startLine: 1, startCol: 5, endLine: 1, endCol: 5, unit: UnitRunes, tabWidth: 4, start: 2, size: 1},
+		{name: "символ вне BMP в UTF-16", content: "x😀y\n", startLine: 1, startCol: 2, endLine: 1, endCol: 2, unit: UnitUTF16, tabWidth: 1, start: 1, size: 2},
+		{name: "после символа вне BMP в UTF-16", content: "x😀y\n", startLine: 1, startCol: 3, endLine: 1, endCol: 3, unit: UnitUTF16, tabWidth: 1, start: 3, size: 1},
+		{name: "после символа вне BMP в символах", content: "x😀y\n", startLine: 1, startCol: 3, endLine: 1, endCol: 3, unit: UnitRunes, tabWidth: 1, start: 2, size: 1},
+		{name: "после символа вне BMP в байтах", content: "x😀y\n", startLine: 1, startCol: 3, endLine: 1, endCol: 3, unit: UnitBytes, tabWidth: 1, start: 5, size: 1},
+		{name: "последняя строка без перевода строки", content: "ab\ncd", startLine: 2, endLine: 2, unit: UnitRunes, tabWidth: 1, start: 3, size: 2},
+		{name: "конец за пределами последней строки", content: "ab\ncd", startLine: 2, startCol: 2, endLine: 2, endCol: 99, unit: UnitRunes, tabWidth: 1, start: 4, size: 1},
+		{name: "строка за пределами файла", content: "ab\ncd", startLine: 2, startCol: 1, endLine: 5, unit: UnitRunes, tabWidth: 1, start: 3, size: 2},
+		{name: "конец раньше начала", content: "abc\n", startLine: 1, startCol: 3, endLine: 1, endCol: 1, unit: UnitRunes, tabWidth: 1, start: 2, size: 0},
+		{name: "пустой файл", content: "", startLine: 1, startCol: 1, endLine: 1, endCol: 1, unit: UnitUTF16, tabWidth: 1, start: 0, size: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines := NewLineIndex([]byte(test.content))
+			start, size := lines.Position(test.startLine, test.startCol, test.endLine, test.endCol, test.unit, test.tabWidth)
+			if start != test.start || size != test.size {
+				t.Errorf("Position = %d, %d, ожидалось %d, %d", start, size, test.start, test.size)
+			}
+		})
+	}
+}
+
+func TestToUnitFromUnit(t *testing.T) {
+	// Байтовые позиции: a - 0, 😀 - 1, \r - 5, \n - 6, б - 7, \t - 9, c - 10, конец - 11.
+	lines := NewLineIndex([]byte("a😀\r\nб\tc"))
+
+	tests := []struct {
+		offset int
+		unit   OffsetUnit
+		units  uint64
+	}{
+		{offset: 0, unit: UnitUTF16, units: 0},
+		{offset: 1, unit: UnitUTF16, units: 1},
+		{offset: 5, unit: UnitUTF16, units: 3},
+		{offset: 7, unit: UnitUTF16, units: 5},
+		{offset: 10, unit: UnitUTF16, units: 7},
+		{offset: 11, unit: UnitUTF16, units: 8},
+		{offset: 5, unit: UnitRunes, units: 2},
+		{offset: 7, unit: UnitRunes, units: 4},
+		{offset: 10, unit: UnitRunes, units: 6},
+		{offset: 7, unit: UnitBytes, units: 7},
+	}
+
+	for _, test := range tests {
+		if units := lines.ToUnit(test.offset, test.unit); units != test.units {
+			t.Errorf("ToUnit(%d, %s) = %d, ожидалось %d", test.offset, test.unit, units, test.units)
+		}
+		if offset := lines.FromUnit(test.units, test.unit); offset != test.offset {
+			t.Errorf("FromUnit(%d, %s) = %d, ожидалось %d", test.units, test.unit, offset, test.offset)
+		}
+	}
+
+	// Позиции за пределами файла ограничиваются его границами.
+	if units := lines.ToUnit(-1, UnitRunes); units != 0 {
+		t.Errorf("ToUnit(-1) = %d, ожидалось 0", units)
+	}
+	if units := lines.ToUnit(100, UnitRunes); units != 7 {
+		t.Errorf("ToUnit(100) = %d, ожидалось 7", units)
+	}
+	if offset := lines.FromUnit(100, UnitUTF16); offset != 11 {
+		t.Errorf("FromUnit(100) = %d, ожидалось 11", offset)
+	}
+
+	// Позиция внутри суррогатной пары UTF-16 - начало символа.
+	if offset := lines.FromUnit(2, UnitUTF16); offset != 1 {
+		t.Errorf("FromUnit(2, utf16) = %d, ожидалось 1", offset)
+	}
+}
@@ -0,0 +1,101 @@
+package checker
+
+import (
+	"cmp"
+	"slices"
+)
+
+// MergeOptions - параметры объединения фрагментов совпадений.
+type MergeOptions struct {
+	MaxGap  uint64 `json:"max_gap"`  // Максимальное расстояние между объединяемыми фрагментами (в единицах позиций).
+	MinSize uint64 `json:"min_size"` // Минимальный размер фрагмента, меньшие фрагменты отбрасываются.
+}
+
+// MergeMatches объединяет пересекающиеся и близкие фрагменты каждой пары файлов,
+// отбрасывает мелкие фрагменты и упорядочивает оставшиеся по убыванию размера.
+// Фрагменты объединяются, если они близки в обоих файлах.
+func MergeMatches(items []*ReportItem, options MergeOptions) {
+	for _, item := range items {
+		item.Matches = mergeItemMatches(item.Matches, options)
+	}
+}
+
+// mergeItemMatches объединяет фрагменты совпадений пары работ.
+func mergeItemMatches(matches []MatchItem, options MergeOptions) []MatchItem {
+	// Группировка фрагментов по паре файлов (и ячеек блокнотов).
+	type filePair struct {
+		file1, file2 string
+		cell1, cell2 int64
+	}
+	groups := make(map[filePair][]MatchItem)
+	for _, m := range matches {
+		key := filePair{m.Work1File, m.Work2File, cellKey(m.Work1Cell), cellKey(m.Work2Cell)}
+		groups[key] = append(groups[key], m)
+	}
+
+	result := make([]MatchItem, 0, len(matches))
+	for _, group := range groups {
+		slices.SortFunc(group, func(a, b MatchItem) int {
+			return cmp.Or(cmp.Compare(a.Work1Start, b.Work1Start), cmp.Compare(a.Work2Start, b.Work2Start))
+		})
+
+		// Объединение соседних фрагментов.
+		merged := []MatchItem{group[0]}
+		for _, m := range group[1:] {
+			last := &merged[len(merged)-1]
+			if gap(last.Work1Start, last.Work1Size, m.Work1Start, m.Work1Size) <= options.MaxGap &&
+				gap(last.Work2Start, last.Work2Size, m.Work2Start, m.Work2Size) <= options.MaxGap {
+				last.Work1Start, last.Work1Size = union(last.Work1Start, last.Work1Size, m.Work1Start, m.Work1Size)
+				last.Work2Start, last.Work2Size = union(last.Work2Start, last.Work2Size, m.Work2Start, m.Work2Size)
+				continue
+			}
+			merged = append(merged, m)
+		}
+
+		// Отбрасывание мелких фрагментов.
+		for _, m := range merged {
+			if min(m.Work1Size, m.Work2Size) >= options.MinSize {
+				result = append(result, m)
+			}
+		}
+	}
+
+	// Упорядочивание по значимости (суммарному размеру фрагмента), при равенстве - по положению,
+	// чтобы порядок не зависел от порядка обхода групп.
+	slices.SortFunc(result, func(a, b MatchItem) int {
+		return cmp.Or(
+			cmp.Compare(b.Work1Size+b.Work2Size, a.Work1Size+a.Work2Size),
+			cmp.Compare(a.Work1File, b.Work1File),
+			cmp.Compare(cellKey(a.Work1Cell), cellKey(b.Work1Cell)),
+			cmp.Compare(a.Work1Start, b.Work1Start),
+			cmp.Compare(a.Work2File, b.Work2File),
+			cmp.Compare(cellKey(a.Work2Cell), cellKey(b.Work2Cell)),
+			cmp.Compare(a.Work2Start, b.Work2Start),
+		)
+	})
+
+	return result
+}
+
+// gap вычисляет расстояние между отрезками (0, если отрезки пересекаются).
+func gap(start1, size1, start2, size2 uint64) uint64 {
+	from, to := max(start1, start2), min(start1+size1, start2+size2)
+	if from <= to {
+		return 0
+	}
+	return from - to
+}
+
+// union вычисляет наименьший отрезок, содержащий оба отрезка.
+func union(start1, size1, start2, size2 uint64) (uint64, uint64) {
+	start := min(start1, start2)
+	return start, max(start1+size1, start2+size2) - start
+}
+
+// cellKey возвращает номер ячейки блокнота для группировки (-1, если фрагмент не в блокноте).
+func cellKey(cell *uint32) int64 {
+	if cell == nil {
+		return -1
+	}
+	return int64(*cell)
+}
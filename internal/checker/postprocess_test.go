@@ -0,0 +1,132 @@
+package checker
+
+import (
+	"slices"
+	"testing"
+)
+
+// fragment - фрагмент совпадения в файлах A.cs обеих работ.
+func fragment(start1, size1, start2, size2 uint64) MatchItem {
+	return MatchItem{Work1File: "A.cs", Work1Start: start1, Work1Size: size1, Work2File: "A.cs", Work2Start: start2, Work2Size: size2}
+}
+
+// inCells - фрагмент в ячейках блокнотов.
+func inCells(m MatchItem, cell1 uint32, cell2 uint32) MatchItem {
+	m.Work1Cell, m.Work2Cell = &cell1, &cell2
+	return m
+}
+
+// inFiles - фрагмент в файлах file1 и file2.
+func inFiles(m MatchItem, file1 string, file2 string) MatchItem {
+	m.Work1File, m.Work2File = file1, file2
+	return m
+}
+
+func TestMergeMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  MergeOptions
+		matches  []MatchItem
+		expected []MatchItem
+	}{
+		{
+			name:     "пересечение при MaxGap=0",
+			matches:  []MatchItem{fragment(10, 20, 100, 20), fragment(0, 15, 95, 10)},
+			expected: []MatchItem{fragment(0, 30, 95, 25)},
+		},
+		{
+			name:     "соприкосновение при MaxGap=0",
+			matches:  []MatchItem{fragment(0, 10, 0, 10), fragment(10, 5, 10, 5)},
+			expected: []MatchItem{fragment(0, 15, 0, 15)},
+		},
+		{
+			name:     "вложенный фрагмент",
+			matches:  []MatchItem{fragment(0, 50, 0, 50), fragment(10, 5, 20, 5)},
+			expected: []MatchItem{fragment(0, 50, 0, 50)},
+		},
+		{
+			name:     "промежуток при MaxGap=0",
+			matches:  []MatchItem{fragment(0, 10, 0, 10), fragment(11, 5, 11, 5)},
+			expected: []MatchItem{fragment(0, 10, 0, 10), fragment(11, 5, 11, 5)},
+		},
+		{
+			name:     "промежуток не больше MaxGap",
+			options:  MergeOptions{MaxGap: 3},
+			matches:  []MatchItem{fragment(0, 10, 0, 10), fragment(13, 5, 12, 5)},
+			expected: []MatchItem{fragment(0, 18, 0, 17)},
+		},
+		{
+			name:     "промежуток больше MaxGap в одном файле",
+			options:  MergeOptions{MaxGap: 3},
+			matches:  []MatchItem{fragment(0, 10, 0, 10), fragment(12, 5, 14, 5)},
+			expected: []MatchItem{fragment(0, 10, 0, 10), fragment(12, 5, 14, 5)},
+		},
+		{
+			name:     "цепочка фрагментов",
+			options:  MergeOptions{MaxGap: 2},
+			matches:  []MatchItem{fragment(24, 6, 24, 6), fragment(0, 10, 0, 10), fragment(12, 10, 12, 10)},
+			expected: []MatchItem{fragment(0, 30, 0, 30)},
+		},
+		{
+			name:     "разные файлы не объединяются",
+			options:  MergeOptions{MaxGap: 100},
+			matches:  []MatchItem{inFiles(fragment(0, 10, 0, 10), "A.cs", "B.cs"), fragment(0, 10, 0, 10)},
+			expected: []MatchItem{fragment(0, 10, 0, 10), inFiles(fragment(0, 10, 0, 10), "A.cs", "B.cs")},
+		},
+		{
+			name:    "ячейки блокнотов",
+			options: MergeOptions{MaxGap: 100},
+			matches: []MatchItem{
+				inCells(fragment(0, 10, 0, 10), 0, 0),
+				inCells(fragment(5, 10, 5, 10), 1, 0),
+				inCells(fragment(10, 10, 10, 10), 0, 0),
+				fragment(0, 4, 0, 4),
+			},
+			expected: []MatchItem{
+				inCells(fragment(0, 20, 0, 20), 0, 0),
+				inCells(fragment(5, 10, 5, 10), 1, 0),
+				fragment(0, 4, 0, 4),
+			},
+		},
+		{
+			name:     "MinSize после объединения",
+			options:  MergeOptions{MinSize: 10},
+			matches:  []MatchItem{fragment(0, 6, 0, 6), fragment(6, 6, 6, 6), fragment(20, 9, 20, 20)},
+			expected: []MatchItem{fragment(0, 12, 0, 12)},
+		},
+		{
+			name:     "MinSize по меньшему из файлов",
+			options:  MergeOptions{MinSize: 10},
+			matches:  []MatchItem{fragment(0, 10, 0, 10), fragment(20, 30, 40, 9)},
+			expected: []MatchItem{fragment(0, 10, 0, 10)},
+		},
+		{
+			name:     "порядок по убыванию размера",
+			matches:  []MatchItem{fragment(0, 5, 0, 5), fragment(100, 20, 100, 20), fragment(50, 10, 50, 10)},
+			expected: []MatchItem{fragment(100, 20, 100, 20), fragment(50, 10, 50, 10), fragment(0, 5, 0, 5)},
+		},
+		{
+			name:     "нет фрагментов",
+			expected: []MatchItem{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := []*ReportItem{{Matches: slices.Clone(test.matches)}}
+			MergeMatches(items, test.options)
+
+			if !slices.EqualFunc(items[0].Matches, test.expected, equalMatch) {
+				t.Errorf("фрагменты %+v, ожидалось %+v", items[0].Matches, test.expected)
+			}
+		})
+	}
+}
+
+// equalMatch сравнивает фрагменты с учётом номеров ячеек.
+func equalMatch(a, b MatchItem) bool {
+	return a.Work1File == b.Work1File && a.Work1Start == b.Work1Start && a.Work1Size == b.Work1Size &&
+		cellKey(a.Work1Cell) == cellKey(b.Work1Cell) &&
+		a.Work2File == b.Work2File && a.Work2Start == b.Work2Start && a.Work2Size == b.Work2Size &&
+		cellKey(a.Work2Cell) == cellKey(b.Work2Cell)
+}
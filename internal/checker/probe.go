@@ -0,0 +1,172 @@
+package checker
+
+import (
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые старшие версии Jplag (форматы результирующего архива).
+const (
+	minJplagMajor = 4
+	maxJplagMajor = 6
+)
+
+var ErrUnsupportedVersion = errors.New("неподдерживаемая версия анализатора")
+var ErrUnsupportedLanguage = errors.New("анализатор не поддерживает язык")
+
+// Файлы внутри jar-архива Jplag.
+const (
+	jplagManifestFile = "META-INF/MANIFEST.MF"
+	jplagServicesFile = "META-INF/services/de.jplag.Language"
+	jplagPomPrefix    = "META-INF/maven/de.jplag/"
+)
+
+var versionRegexp = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// Идентификаторы языков Jplag, не совпадающие с названием пакета de.jplag.<пакет>.
+// Ключ - пакет или пакет с подпакетом (для языков, разделяющих пакет).
+var jarLanguageIDs = map[string]string{
+	"golang":    "go",
+	"multilang": "multi",
+	"emf.model": "emf-model",
+}
+
+type Capabilities struct {
+	Engine    string   `json:"engine"`
+	Version   Version  `json:"version"`
+	Languages []string `json:"languages"`
+}
+
+// ProbeJplag определяет версию Jplag и доступные языки по содержимому jar-архива.
+func ProbeJplag(checkerPath string) (Capabilities, error) {
+	result := Capabilities{Engine: "jplag"}
+
+	jar, err := zip.OpenReader(checkerPath)
+	if err != nil {
+		return result, err
+	}
+	defer jar.Close()
+
+	for _, f := range jar.File {
+		switch {
+		// Версия из сведений maven (например, META-INF/maven/de.jplag/cli/pom.properties).
+		case strings.HasPrefix(f.Name, jplagPomPrefix) && strings.HasSuffix(f.Name, "/pom.properties"):
+			if result.Version == (Version{}) {
+				result.Version, _ = readJarVersion(f, "version=")
+			}
+
+		// Версия из манифеста.
+		case f.Name == jplagManifestFile:
+			if version, err := readJarVersion(f, "Implementation-Version:"); err == nil {
+				result.Version = version
+			}
+
+		// Языки, зарегистрированные как сервисы (de.jplag.<язык>.<Класс>).
+		case f.Name == jplagServicesFile:
+			result.Languages, err = readJarLanguages(f)
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	slices.Sort(result.Languages)
+	return result, nil
+}
+
+// Check проверяет, что версия анализатора поддерживается. Если версию определить не удалось, проверка пропускается.
+func (c Capabilities) Check() error {
+	if c.Version.Major != 0 && (c.Version.Major < minJplagMajor || c.Version.Major > maxJplagMajor) {
+		return fmt.Errorf("%w: %s (поддерживаются %d.x - %d.x)", ErrUnsupportedVersion, c.Version, minJplagMajor, maxJplagMajor)
+	}
+	return nil
+}
+
+// CheckLanguage проверяет, что язык найден среди языков анализатора. Если языки определить не удалось, проверка пропускается.
+// Языки определяются по названиям классов без запуска Jplag, поэтому результат проверки - предупреждение, а не отказ:
+// окончательно язык проверяет Jplag при анализе.
+func (c Capabilities) CheckLanguage(language string) error {
+	if len(c.Languages) != 0 && !slices.Contains(c.Languages, language) {
+		return fmt.Errorf("%w \"%s\" (найдены: %s)", ErrUnsupportedLanguage, language, strings.Join(c.Languages, ", "))
+	}
+	return nil
+}
+
+// String возвращает версию в виде major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// readJarVersion читает версию из строки файла, начинающейся с key.
+func readJarVersion(f *zip.File, key string) (Version, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return Version{}, err
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, key) {
+			return parseVersion(line[len(key):])
+		}
+	}
+
+	return Version{}, io.EOF
+}
+
+// readJarLanguages читает идентификаторы языков из файла сервисов (de.jplag.<пакет>.<Класс>).
+// Идентификатор языка - название пакета, кроме языков из jarLanguageIDs.
+func readJarLanguages(f *zip.File) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var result []string
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		parts := strings.Split(line, ".")
+		if len(parts) < 4 || parts[0] != "de" || parts[1] != "jplag" {
+			continue
+		}
+
+		id := parts[2]
+		if mapped, ok := jarLanguageIDs[parts[2]+"."+parts[3]]; ok && len(parts) > 4 {
+			id = mapped
+		} else if mapped, ok = jarLanguageIDs[parts[2]]; ok {
+			id = mapped
+		}
+
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// parseVersion читает версию вида major.minor.patch.
+func parseVersion(s string) (Version, error) {
+	groups := versionRegexp.FindStringSubmatch(s)
+	if groups == nil {
+		return Version{}, fmt.Errorf("не удалось прочитать версию \"%s\"", s)
+	}
+
+	var version Version
+	version.Major, _ = strconv.Atoi(groups[1])
+	version.Minor, _ = strconv.Atoi(groups[2])
+	version.Patch, _ = strconv.Atoi(groups[3])
+
+	return version, nil
+}
@@ -0,0 +1,125 @@
+package checker
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"testing"
+)
+
+// writeJar создаёт jar-архив с файлами files.
+func writeJar(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	jarPath := path.Join(t.TempDir(), "jplag.jar")
+	f, err := os.Create(jarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return jarPath
+}
+
+func TestProbeJplag(t *testing.T) {
+	jar := writeJar(t, map[string]string{
+		jplagPomPrefix + "cli/pom.properties": "groupId=de.jplag\nversion=5.1.0\n",
+		jplagManifestFile:                     "Manifest-Version: 1.0\nImplementation-Version: 6.1.0-SNAPSHOT\n",
+		jplagServicesFile: `# языки Jplag
+de.jplag.java.JavaLanguage
+de.jplag.python3.PythonLanguage
+de.jplag.golang.GoLanguage
+de.jplag.emf.EmfLanguage
+de.jplag.emf.model.EmfModelLanguage
+de.jplag.multilang.MultiLanguage
+de.jplag.java.JavaLanguage
+org.example.OtherLanguage
+`,
+	})
+
+	capabilities, err := ProbeJplag(jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Версия из манифеста заменяет версию из сведений maven.
+	if capabilities.Version != (Version{Major: 6, Minor: 1}) {
+		t.Errorf("версия = %s, ожидалось 6.1.0", capabilities.Version)
+	}
+
+	// Идентификаторы языков, а не названия пакетов.
+	expected := []string{"emf", "emf-model", "go", "java", "multi", "python3"}
+	if !slices.Equal(capabilities.Languages, expected) {
+		t.Errorf("языки = %v, ожидалось %v", capabilities.Languages, expected)
+	}
+
+	if err = capabilities.Check(); err != nil {
+		t.Error(err)
+	}
+	if err = capabilities.CheckLanguage("go"); err != nil {
+		t.Error(err)
+	}
+	if err = capabilities.CheckLanguage("golang"); !errors.Is(err, ErrUnsupportedLanguage) {
+		t.Errorf("CheckLanguage(golang) = %v, ожидалось %v", err, ErrUnsupportedLanguage)
+	}
+}
+
+func TestProbeJplagWithoutInfo(t *testing.T) {
+	// Версия только из сведений maven, языков нет.
+	jar := writeJar(t, map[string]string{
+		jplagPomPrefix + "jplag/pom.properties": "version=4.2.0\n",
+		"de/jplag/JPlag.class":                  "",
+	})
+
+	capabilities, err := ProbeJplag(jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capabilities.Version != (Version{Major: 4, Minor: 2}) || len(capabilities.Languages) != 0 {
+		t.Errorf("возможности = %+v", capabilities)
+	}
+
+	// Без списка языков проверка языка пропускается.
+	if err = capabilities.CheckLanguage("anything"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err = ProbeJplag(path.Join(t.TempDir(), "missing.jar")); err == nil {
+		t.Error("ProbeJplag: ожидалась ошибка для отсутствующего файла")
+	}
+}
+
+func TestCapabilitiesCheck(t *testing.T) {
+	tests := []struct {
+		version Version
+		ok      bool
+	}{
+		{version: Version{}, ok: true},
+		{version: Version{Major: 3, Minor: 3}, ok: false},
+		{version: Version{Major: 4}, ok: true},
+		{version: Version{Major: 6, Minor: 1}, ok: true},
+		{version: Version{Major: 7}, ok: false},
+	}
+
+	for _, test := range tests {
+		err := Capabilities{Version: test.version}.Check()
+		if (err == nil) != test.ok || (err != nil && !errors.Is(err, ErrUnsupportedVersion)) {
+			t.Errorf("Check(%s) = %v", test.version, err)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package checker
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Служебные файлы результирующего архива Jplag.
+const (
+	overviewFile            = "overview.json"            // Jplag 4.x - 5.x.
+	runInformationFile      = "runInformation.json"      // Jplag 6.x.
+	submissionMappingsFile  = "submissionMappings.json"  // Jplag 6.x.
+	submissionFileIndexFile = "submissionFileIndex.json" // Jplag 5.x - 6.x.
+	optionsFile             = "options.json"
+	comparisonsDir          = "comparisons"
+)
+
+// jplagReport - содержимое результирующего архива Jplag.
+type jplagReport struct {
+	info        RunInfo
+	format      int                   // старшая версия формата отчёта.
+	comparisons []*zip.File           // элементы архива со сравнениями работ.
+	files       map[string]fileSet    // файлы работ по id работы в Jplag (nil, если индекса нет).
+	roots       []string              // каталоги, переданные анализатору.
+	submissions map[string]submission // сопоставленные с каталогами работы по id работы в Jplag.
+	lines       map[string]*LineIndex // индексы строк прочитанных файлов по пути на диске.
+}
+
+// fileSet - множество путей к файлам работы в отчёте.
+type fileSet map[string]struct{}
+
+// submission - работа, сопоставленная с каталогом на диске.
+type submission struct {
+	name   string // id работы в Jplag.
+	workID uint64 // id работы (0, если название каталога не является id).
+	dir    string // каталог работы на диске.
+	prefix string // префикс путей к файлам работы в отчёте.
+}
+
+// readReport читает служебные файлы результирующего архива.
+// Roots: каталоги, переданные анализатору.
+func readReport(zipReader *zip.Reader, roots []string) (*jplagReport, error) {
+	entries := make(map[string]*zip.File, len(zipReader.File))
+	for _, f := range zipReader.File {
+		entries[f.Name] = f
+	}
+
+	report := &jplagReport{
+		roots:       roots,
+		submissions: make(map[string]submission),
+		lines:       make(map[string]*LineIndex),
+	}
+
+	var err error
+	if f, ok := entries[overviewFile]; ok {
+		err = report.readOverview(f, entries)
+	} else if f, ok := entries[runInformationFile]; ok {
+		err = report.readRunInformation(f, entries)
+	} else {
+		err = ErrUnknownReport
+	}
+
+	return report, err
+}
+
+// readOverview читает служебные файлы отчёта Jplag 4.x - 5.x.
+func (r *jplagReport) readOverview(f *zip.File, entries map[string]*zip.File) error {
+	var overview OverviewDTO
+	if err := readJSON(f, &overview); err != nil {
+		return err
+	}
+
+	r.format = overview.JplagVersion.Major
+	r.info = RunInfo{
+		CheckerVersion:   overview.JplagVersion,
+		Language:         readLanguage(overview.Language),
+		Date:             overview.DateOfExecution,
+		ExecutionTime:    overview.ExecutionTime,
+		TotalComparisons: overview.TotalComparisons,
+	}
+
+	// Работы, которые анализатор не смог обработать (4.x - список, 5.x - словарь).
+	var failedList []string
+	var failedMap map[string]string
+	if json.Unmarshal(overview.FailedSubmissionNames, &failedList) == nil {
+		for _, name := range failedList {
+			r.info.FailedSubmissions = append(r.info.FailedSubmissions, FailedSubmission{Name: name})
+		}
+	} else if json.Unmarshal(overview.FailedSubmissionNames, &failedMap) == nil {
+		for name, state := range failedMap {
+			r.info.FailedSubmissions = append(r.info.FailedSubmissions, FailedSubmission{Name: name, State: state})
+		}
+	}
+
+	// Индекс файлов работ (5.x).
+	if f, ok := entries[submissionFileIndexFile]; ok {
+		var index SubmissionFileIndexDTO
+		if err := readJSON(f, &index); err != nil {
+			return err
+		}
+		r.files = readFileIndex(index.SubmissionFileIndexes)
+	}
+
+	return r.findComparisons(overview.SubmissionIDsToComparisonFileName, entries)
+}
+
+// readRunInformation читает служебные файлы отчёта Jplag 6.x.
+func (r *jplagReport) readRunInformation(f *zip.File, entries map[string]*zip.File) error {
+	var runInformation RunInformationDTO
+	if err := readJSON(f, &runInformation); err != nil {
+		return err
+	}
+
+	version := runInformation.ReportViewerVersion
+	if runInformation.JplagVersion != nil {
+		version = *runInformation.JplagVersion
+	}
+
+	date := runInformation.DateOfExecution
+	if date == "" {
+		date = runInformation.SubmissionDate
+	}
+
+	r.format = 6
+	r.info = RunInfo{
+		CheckerVersion:   version,
+		Date:             date,
+		ExecutionTime:    runInformation.ExecutionTime,
+		TotalComparisons: runInformation.TotalComparisons,
+	}
+	for _, failed := range runInformation.FailedSubmissions {
+		r.info.FailedSubmissions = append(r.info.FailedSubmissions, FailedSubmission{
+			Name:  failed.SubmissionID,
+			State: failed.SubmissionState,
+		})
+	}
+
+	// Язык анализа.
+	if f, ok := entries[optionsFile]; ok {
+		var options OptionsDTO
+		if err := readJSON(f, &options); err != nil {
+			return err
+		}
+		r.info.Language = readLanguage(options.Language)
+	}
+
+	// Индекс файлов работ.
+	if f, ok := entries[submissionFileIndexFile]; ok {
+		var index FileIndexesDTO
+		if err := readJSON(f, &index); err != nil {
+			return err
+		}
+		r.files = readFileIndex(index.FileIndexes)
+	}
+
+	// Соответствие работ и файлов сравнений.
+	f, ok := entries[submissionMappingsFile]
+	if !ok {
+		return fmt.Errorf("%w: нет файла %s", ErrUnknownReport, submissionMappingsFile)
+	}
+	var mappings SubmissionMappingsDTO
+	if err := readJSON(f, &mappings); err != nil {
+		return err
+	}
+
+	return r.findComparisons(mappings.SubmissionIDsToComparisonFileName, entries)
+}
+
+// findComparisons находит элементы архива со сравнениями работ.
+func (r *jplagReport) findComparisons(names map[string]map[string]string, entries map[string]*zip.File) error {
+	found := make(map[string]struct{})
+
+	for _, second := range names {
+		for _, name := range second {
+			if _, ok := found[name]; ok {
+				continue
+			}
+			found[name] = struct{}{}
+
+			// В Jplag 6.x сравнения хранятся в отдельном каталоге.
+			f, ok := entries[name]
+			if !ok {
+				f, ok = entries[path.Join(comparisonsDir, name)]
+			}
+			if !ok {
+				return fmt.Errorf("%w: нет файла сравнения %s", ErrUnknownReport, name)
+			}
+
+			r.comparisons = append(r.comparisons, f)
+		}
+	}
+
+	return nil
+}
+
+// readComparison читает сравнение двух работ.
+func (r *jplagReport) readComparison(f *zip.File) (*ResultDTO, error) {
+	// Jplag 4.x - 5.x.
+	if r.format < 6 {
+		var result ResultDTO
+		if err := readJSON(f, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	// Jplag 6.x.
+	var resultV6 ResultV6DTO
+	if err := readJSON(f, &resultV6); err != nil {
+		return nil, err
+	}
+
+	result := &ResultDTO{
+		ID1:              resultV6.FirstSubmissionID,
+		ID2:              resultV6.SecondSubmissionID,
+		Similarities:     resultV6.Similarities,
+		Matches:          make([]MatchDTO, len(resultV6.Matches)),
+		FirstSimilarity:  resultV6.FirstSimilarity,
+		SecondSimilarity: resultV6.SecondSimilarity,
+	}
+	for i, m := range resultV6.Matches {
+		result.Matches[i] = MatchDTO{
+			File1:     m.FirstFileName,
+			File2:     m.SecondFileName,
+			Start1:    m.StartInFirst.Line,
+			Start2:    m.StartInSecond.Line,
+			Start1Col: m.StartInFirst.Column,
+			Start2Col: m.StartInSecond.Column,
+			End1:      m.EndInFirst.Line,
+			End2:      m.EndInSecond.Line,
+			End1Col:   m.EndInFirst.Column,
+			End2Col:   m.EndInSecond.Column,
+		}
+	}
+
+	return result, nil
+}
+
+// submission сопоставляет работу из отчёта с каталогом на диске.
+// Если анализатору передано несколько каталогов, Jplag называет работу "<каталог>_<работа>",
+// а пути к файлам указывает в виде "<каталог>/<работа>/<файл>".
+func (r *jplagReport) submission(name string) (submission, error) {
+	if sub, ok := r.submissions[name]; ok {
+		return sub, nil
+	}
+
+	// Файлы работы по индексу.
+	files := r.files[name]
+
+	for _, root := range r.roots {
+		base := path.Base(root)
+
+		// Варианты названия каталога работы и префикса путей к файлам.
+		candidates := []struct{ dir, prefix string }{
+			{dir: name, prefix: name + "/"},
+		}
+		if strings.HasPrefix(name, base+"_") {
+			dir := name[len(base)+1:]
+			candidates = append([]struct{ dir, prefix string }{{dir: dir, prefix: base + "/" + dir + "/"}}, candidates...)
+		}
+
+		for _, candidate := range candidates {
+			// Работа - подкаталог каталога анализа.
+			if candidate.dir == "" || candidate.dir == "." || candidate.dir == ".." || strings.Contains(candidate.dir, "/") {
+				continue
+			}
+
+			dir := path.Join(root, candidate.dir)
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+
+			// Пути к файлам по индексу должны начинаться с префикса.
+			if !files.hasPrefix(candidate.prefix) {
+				continue
+			}
+
+			sub := submission{
+				name:   name,
+				dir:    dir,
+				prefix: candidate.prefix,
+			}
+			sub.workID, _ = strconv.ParseUint(path.Base(dir), 10, 64)
+
+			r.submissions[name] = sub
+			return sub, nil
+		}
+	}
+
+	return submission{}, fmt.Errorf("работа %s не найдена среди каталогов анализа", name)
+}
+
+// file возвращает путь к файлу относительно каталога работы.
+func (r *jplagReport) file(sub submission, name string) (string, error) {
+	// Если есть индекс, файл должен в нём присутствовать.
+	if files, ok := r.files[sub.name]; ok {
+		if _, ok = files[name]; !ok {
+			return "", fmt.Errorf("файл %s отсутствует в индексе работы %s", name, sub.name)
+		}
+	}
+
+	if !strings.HasPrefix(name, sub.prefix) {
+		return "", fmt.Errorf("файл %s не относится к работе %s", name, sub.name)
+	}
+
+	// Файл должен находиться внутри каталога работы.
+	file := path.Clean(name[len(sub.prefix):])
+	if file == "." || file == ".." || strings.HasPrefix(file, "../") || path.IsAbs(file) {
+		return "", fmt.Errorf("файл %s не относится к работе %s", name, sub.name)
+	}
+
+	return file, nil
+}
+
+// lineIndex возвращает индекс строк файла. Индекс строится один раз для всех совпадений.
+func (r *jplagReport) lineIndex(filePath string) (*LineIndex, error) {
+	if lines, ok := r.lines[filePath]; ok {
+		return lines, nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := NewLineIndex(content)
+	r.lines[filePath] = lines
+	return lines, nil
+}
+
+// hasPrefix проверяет, что все пути начинаются с префикса.
+func (s fileSet) hasPrefix(prefix string) bool {
+	for name := range s {
+		if !strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// readFileIndex читает индекс файлов работ.
+// Jplag 5.0 хранит список файлов, более поздние версии - словарь файл: сведения о файле.
+func readFileIndex(index map[string]json.RawMessage) map[string]fileSet {
+	result := make(map[string]fileSet, len(index))
+
+	for name, raw := range index {
+		files := make(fileSet)
+
+		var list []string
+		var dict map[string]json.RawMessage
+		if json.Unmarshal(raw, &list) == nil {
+			for _, file := range list {
+				files[file] = struct{}{}
+			}
+		} else if json.Unmarshal(raw, &dict) == nil {
+			for file := range dict {
+				files[file] = struct{}{}
+			}
+		}
+
+		result[name] = files
+	}
+
+	return result
+}
+
+// readLanguage читает язык анализа (строка или объект с полем name).
+func readLanguage(raw json.RawMessage) string {
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return name
+	}
+
+	var language struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(raw, &language) == nil {
+		return language.Name
+	}
+
+	return ""
+}
+
+// readJSON читает элемент архива в формате json.
+func readJSON(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+
+	return nil
+}
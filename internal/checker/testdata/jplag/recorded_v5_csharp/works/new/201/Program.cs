@@ -0,0 +1,10 @@
+using System;
+
+class Program
+{
+	static void Main()
+	{
+		var s = "🙂 ok";
+		Console.WriteLine(s);
+	}
+}
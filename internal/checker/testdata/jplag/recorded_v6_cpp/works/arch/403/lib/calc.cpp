@@ -0,0 +1,7 @@
+int calc() {
+    int a = 0;
+    for (int i = 0; i < 10; ++i) {
+        a += i;
+    }
+    return a;
+}
@@ -0,0 +1,10 @@
+package demo;
+public class App {
+    public static void main(String[] args) {
+        System.out.println("Привет, мир");
+    }
+
+    static int sum(int a, int b) {
+        return a + b;
+    }
+}
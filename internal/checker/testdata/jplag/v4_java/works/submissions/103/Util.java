@@ -0,0 +1,5 @@
+class Util {
+    static int sum(int x, int y) {
+        return x + y;
+    }
+}
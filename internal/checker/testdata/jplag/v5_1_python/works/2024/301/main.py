@@ -0,0 +1,8 @@
+def сумма(числа):
+    итог = 0
+    for ч in числа:
+        итог += ч
+    return итог
+
+
+print(сумма([1, 2, 3]))
@@ -0,0 +1,6 @@
+# решение
+def total(values):
+    итог = 0
+    for ч in values:
+        итог += ч
+    return итог
@@ -0,0 +1,9 @@
+using System;
+class P
+{
+    static void Main()
+    {
+        var s = "🙂 ok";
+        Console.WriteLine(s);
+    }
+}
@@ -0,0 +1,9 @@
+#include <iostream>
+
+int main() {
+    int a = 0;
+    for (int i = 0; i < 10; ++i) {
+        a += i;
+    }
+    std::cout << a << std::endl;
+}
@@ -0,0 +1,6 @@
+#include <iostream>
+int main() {
+    int s = 0;
+    for (int k = 0; k < 10; ++k) s += k;
+    std::cout << s;
+}
\ No newline at end of file
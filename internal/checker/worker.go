@@ -0,0 +1,212 @@
+package checker
+
+import (
+	"CodeBorrowing/internal/logger"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Главный класс постоянного процесса Jplag.
+const workerMainClass = "JplagWorker"
+
+var ErrWorkerArgs = errors.New("аргумент анализатора содержит перенос строки")
+var ErrWorkerAnalysis = errors.New("jplag: ошибка анализа")
+var ErrWorkerTimeout = errors.New("процесс Jplag не ответил вовремя")
+
+// jplagWorker - постоянный процесс JVM, выполняющий анализ Jplag без повторного запуска JVM.
+// Протокол описан в jplag-worker/JplagWorker.java.
+type jplagWorker struct {
+	logger      *logger.Logger
+	checkerPath string
+	workerPath  string
+	timeout     time.Duration
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// newJplagWorker создаёт постоянный процесс Jplag. Процесс запускается при первом анализе.
+// CheckerPath: путь к jar-архиву Jplag.
+// WorkerPath: путь к jar-архиву jplag-worker.
+// Timeout: время ожидания ответа процесса (0 - без ограничения).
+func newJplagWorker(logger *logger.Logger, checkerPath string, workerPath string, timeout time.Duration) *jplagWorker {
+	return &jplagWorker{
+		logger:      logger,
+		checkerPath: checkerPath,
+		workerPath:  workerPath,
+		timeout:     timeout,
+	}
+}
+
+// Run выполняет анализ с аргументами командной строки Jplag.
+// Если процесс завершился, он перезапускается и анализ повторяется один раз.
+// Если процесс не ответил за время ожидания, он завершается без повтора анализа
+// и будет запущен заново при следующем анализе.
+func (w *jplagWorker) Run(args []string) error {
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "\r\n") {
+			return ErrWorkerArgs
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		// Запуск процесса, если он не запущен.
+		if w.cmd == nil {
+			if err = w.start(); err != nil {
+				return err
+			}
+		}
+
+		var done bool
+		done, err = w.send(args)
+		if done {
+			return err
+		}
+		if errors.Is(err, ErrWorkerTimeout) {
+			w.stop()
+			return err
+		}
+
+		// Процесс не отвечает - перезапуск.
+		w.logger.Warnf("Процесс Jplag завершился (%v). Перезапуск", err)
+		w.stop()
+	}
+
+	return err
+}
+
+// Close завершает процесс.
+func (w *jplagWorker) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stop()
+	return nil
+}
+
+// start запускает процесс и ожидает сигнала готовности.
+func (w *jplagWorker) start() error {
+	classPath := w.checkerPath + string(filepath.ListSeparator) + w.workerPath
+	cmd := exec.Command("java", "-cp", classPath, workerMainClass)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	w.cmd = cmd
+	w.stdin = stdin
+	w.stdout = bufio.NewReader(stdout)
+
+	// Ожидание готовности.
+	line, err := w.readLine()
+	if err != nil || line != "READY" {
+		w.stop()
+		return fmt.Errorf("процесс Jplag не запустился: %q, %v", line, err)
+	}
+
+	w.logger.Info("Процесс Jplag запущен")
+	return nil
+}
+
+// stop завершает процесс.
+func (w *jplagWorker) stop() {
+	if w.cmd == nil {
+		return
+	}
+
+	_ = w.stdin.Close()
+	_ = w.cmd.Process.Kill()
+	_ = w.cmd.Wait()
+	w.cmd = nil
+}
+
+// send отправляет команду анализа и читает ответ.
+// Done = false, если процесс не ответил и его требуется перезапустить.
+func (w *jplagWorker) send(args []string) (done bool, err error) {
+	// Отправка команды.
+	sb := strings.Builder{}
+	sb.WriteString("RUN " + strconv.Itoa(len(args)) + "\n")
+	for _, arg := range args {
+		sb.WriteString(arg + "\n")
+	}
+	if _, err = io.WriteString(w.stdin, sb.String()); err != nil {
+		return false, err
+	}
+
+	// Чтение ответа.
+	line, err := w.readLine()
+	if err != nil {
+		return false, err
+	}
+
+	if line == "OK" {
+		return true, nil
+	}
+	if message, ok := strings.CutPrefix(line, "ERROR "); ok {
+		return true, fmt.Errorf("%w: %s", ErrWorkerAnalysis, message)
+	}
+
+	return false, fmt.Errorf("неизвестный ответ процесса Jplag: %q", line)
+}
+
+// readLine читает строку ответа процесса. Если процесс не ответил за время ожидания,
+// возвращает ErrWorkerTimeout: чтение прерывается завершением процесса (stop).
+func (w *jplagWorker) readLine() (string, error) {
+	if w.timeout <= 0 {
+		return readWorkerLine(w.stdout)
+	}
+
+	type response struct {
+		line string
+		err  error
+	}
+	done := make(chan response, 1)
+	go func(stdout *bufio.Reader) {
+		line, err := readWorkerLine(stdout)
+		done <- response{line, err}
+	}(w.stdout)
+
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.line, r.err
+	case <-timer.C:
+		return "", fmt.Errorf("%w: %v", ErrWorkerTimeout, w.timeout)
+	}
+}
+
+// readWorkerLine читает строку из вывода процесса.
+func readWorkerLine(stdout *bufio.Reader) (string, error) {
+	line, err := stdout.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,217 @@
+package checker
+
+import (
+	"CodeBorrowing/internal/logger"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Переменные среды теста постоянного процесса Jplag: пути к jar-архивам Jplag и jplag-worker.
+// Пример: JPLAG_JAR=./jplag.jar JPLAG_WORKER_JAR=./jplag-worker.jar go test ./internal/checker -run TestJplagWorker
+const (
+	envTestJplagJar  = "JPLAG_JAR"
+	envTestWorkerJar = "JPLAG_WORKER_JAR"
+)
+
+// TestJplagWorker сравнивает результаты постоянного процесса Jplag и отдельного процесса
+// на работах эталонных случаев testdata/jplag: первый каталог случая - новые работы, остальные - старые.
+func TestJplagWorker(t *testing.T) {
+	jplagJar, workerJar := os.Getenv(envTestJplagJar), os.Getenv(envTestWorkerJar)
+	if jplagJar == "" || workerJar == "" {
+		t.Skipf("переменные среды %s и %s не установлены", envTestJplagJar, envTestWorkerJar)
+	}
+	if _, err := exec.LookPath("java"); err != nil {
+		t.Skip(err)
+	}
+	capabilities, err := ProbeJplag(jplagJar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases, err := os.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Постоянный процесс, общий для всех случаев.
+	appLogger := logger.NewLogger(t.TempDir())
+	worker := newJplagWorker(appLogger, jplagJar, workerJar, 0)
+	t.Cleanup(func() { _ = worker.Close() })
+
+	for _, entry := range cases {
+		if !entry.IsDir() {
+			continue
+		}
+
+		t.Run(entry.Name(), func(t *testing.T) {
+			dir := path.Join(goldenDir, entry.Name())
+			c := readGoldenCase(t, dir)
+			if !slices.Contains(capabilities.Languages, c.Language) {
+				t.Skipf("Jplag %s не поддерживает язык %s", capabilities.Version, c.Language)
+			}
+
+			roots := make([]string, len(c.Roots))
+			for i, root := range c.Roots {
+				var err error
+				if roots[i], err = filepath.Abs(path.Join(dir, "works", root)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			options := Options{Language: c.Language, OffsetUnit: c.OffsetUnit, TabWidth: c.TabWidth}
+			oneShot := NewJplagChecker(appLogger, jplagJar, t.TempDir(), "", 0, options)
+			withWorker := NewJplagChecker(appLogger, jplagJar, t.TempDir(), "", 0, options).(*jplag)
+			withWorker.worker = worker
+
+			expected := runForCompare(t, oneShot, roots[:1], roots[1:])
+			actual := runForCompare(t, withWorker, roots[:1], roots[1:])
+			if worker.cmd == nil {
+				t.Fatal("постоянный процесс не запущен: анализ выполнен отдельным процессом")
+			}
+			if expected != actual {
+				t.Errorf("результат постоянного процесса отличается от отдельного процесса:\n%s", diffLines(expected, actual))
+			}
+		})
+	}
+
+	// Ошибка анализа не завершает постоянный процесс.
+	err = worker.Run([]string{"-new", t.TempDir(), "-l", "unknown", "-r", path.Join(t.TempDir(), ResultFile)})
+	if !errors.Is(err, ErrWorkerAnalysis) {
+		t.Errorf("ошибка %v, ожидалась ошибка анализа", err)
+	}
+	if worker.cmd == nil {
+		t.Error("постоянный процесс завершился после ошибки анализа")
+	}
+}
+
+// runForCompare запускает анализ и возвращает результат в формате json без времени запуска анализа.
+func runForCompare(t *testing.T, c Checker, newWorks []string, oldWorks []string) string {
+	t.Helper()
+
+	items, err := c.Run(newWorks, oldWorks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.SortFunc(items, func(a, b *ReportItem) int {
+		if a.Work1Name != b.Work1Name {
+			return strings.Compare(a.Work1Name, b.Work1Name)
+		}
+		return strings.Compare(a.Work2Name, b.Work2Name)
+	})
+
+	info := c.(RunInfoProvider).LastRunInfo()
+	info.Date, info.ExecutionTime = "", 0
+	slices.SortFunc(info.FailedSubmissions, func(a, b FailedSubmission) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	result, err := json.MarshalIndent(struct {
+		Info  RunInfo       `json:"info"`
+		Items []*ReportItem `json:"items"`
+	}{Info: info, Items: items}, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(result)
+}
+
+// fakeJava - сценарий, заменяющий java в тесте времени ожидания: постоянный процесс отвечает OK
+// и зависает на анализе языка hang, отдельный процесс создаёт пустой файл результата.
+const fakeJava = `#!/bin/sh
+if [ "$1" = "-jar" ]; then
+	while [ $# -gt 0 ]; do
+		if [ "$1" = "-r" ]; then : > "$2"; fi
+		shift
+	done
+	exit 0
+fi
+echo READY
+while read -r command; do
+	n=${command#RUN }
+	hang=0
+	i=0
+	while [ $i -lt $n ]; do
+		read -r arg
+		if [ "$arg" = "hang" ]; then hang=1; fi
+		i=$((i + 1))
+	done
+	if [ $hang = 1 ]; then exec sleep 30; fi
+	echo OK
+done
+`
+
+// setFakeJava устанавливает сценарий fakeJava вместо java.
+func setFakeJava(t *testing.T) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("сценарий java требует sh")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(path.Join(dir, "java"), []byte(fakeJava), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(filepath.ListSeparator)+os.Getenv("PATH"))
+}
+
+func TestJplagWorkerTimeout(t *testing.T) {
+	setFakeJava(t)
+
+	worker := newJplagWorker(logger.NewLogger(t.TempDir()), "jplag.jar", "jplag-worker.jar", 200*time.Millisecond)
+	t.Cleanup(func() { _ = worker.Close() })
+
+	if err := worker.Run([]string{"-l", "java"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Процесс не ответил вовремя: завершается без повтора анализа.
+	started := time.Now()
+	err := worker.Run([]string{"-l", "hang"})
+	if !errors.Is(err, ErrWorkerTimeout) {
+		t.Errorf("ошибка %v, ожидалось превышение времени ожидания", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("ожидание ответа %v", elapsed)
+	}
+	if worker.cmd != nil {
+		t.Error("процесс не завершён после превышения времени ожидания")
+	}
+
+	// Следующий анализ запускает процесс заново.
+	if err = worker.Run([]string{"-l", "java"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestJplagWorkerFallback проверяет запуск отдельного процесса, если постоянный процесс не ответил вовремя.
+func TestJplagWorkerFallback(t *testing.T) {
+	setFakeJava(t)
+
+	root := t.TempDir()
+	for _, name := range []string{"101", "102"} {
+		if err := os.Mkdir(path.Join(root, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewJplagChecker(logger.NewLogger(t.TempDir()), "jplag.jar", t.TempDir(), "jplag-worker.jar",
+		200*time.Millisecond, Options{Language: "hang"}).(*jplag)
+	t.Cleanup(func() { _ = c.Close() })
+
+	resultPath := path.Join(t.TempDir(), ResultFile)
+	if err := c.exec([]string{root}, nil, resultPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(resultPath); err != nil {
+		t.Errorf("анализ не выполнен отдельным процессом: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Build строит кластеры работ - компоненты связности графа схожести,
+// в котором остаются только рёбра со схожестью не ниже threshold.
+// Кластеры упорядочены по убыванию средней схожести.
+func Build(edges []Edge, threshold float64) []Cluster {
+	// Система непересекающихся множеств.
+	parent := make(map[uint64]uint64)
+	var find func(id uint64) uint64
+	find = func(id uint64) uint64 {
+		p, ok := parent[id]
+		if !ok || p == id {
+			parent[id] = id
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+
+	// Объединение работ по рёбрам выше порога.
+	for _, e := range edges {
+		if e.Similarity >= threshold {
+			parent[find(e.Work1ID)] = find(e.Work2ID)
+		}
+	}
+
+	// Группировка работ по компонентам связности.
+	members := make(map[uint64][]uint64)
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+	}
+
+	// Сумма и максимум схожести рёбер внутри компонент.
+	type stat struct {
+		sum   float64
+		max   float64
+		count int
+	}
+	stats := make(map[uint64]*stat)
+	for _, e := range edges {
+		if e.Similarity < threshold {
+			continue
+		}
+
+		root := find(e.Work1ID)
+		s, ok := stats[root]
+		if !ok {
+			s = &stat{}
+			stats[root] = s
+		}
+		s.sum += e.Similarity
+		s.max = max(s.max, e.Similarity)
+		s.count++
+	}
+
+	// Формирование кластеров (одиночные работы кластером не считаются).
+	var result []Cluster
+	for root, ids := range members {
+		s, ok := stats[root]
+		if len(ids) < 2 || !ok {
+			continue
+		}
+
+		slices.Sort(ids)
+		result = append(result, Cluster{
+			Members:       ids,
+			AvgSimilarity: s.sum / float64(s.count),
+			MaxSimilarity: s.max,
+		})
+	}
+
+	slices.SortFunc(result, func(a, b Cluster) int {
+		if c := cmp.Compare(b.AvgSimilarity, a.AvgSimilarity); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Members[0], b.Members[0])
+	})
+
+	return result
+}
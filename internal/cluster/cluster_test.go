@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"CodeBorrowing/internal/checker"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	edges := []Edge{
+		// Компонента 1-2-3 связана цепочкой рёбер выше порога.
+		{Work1ID: 1, Work2ID: 2, Similarity: 0.9},
+		{Work1ID: 3, Work2ID: 2, Similarity: 0.7},
+		// Ребро ниже порога не объединяет компоненты.
+		{Work1ID: 3, Work2ID: 4, Similarity: 0.3},
+		// Ребро ровно на пороге учитывается.
+		{Work1ID: 4, Work2ID: 5, Similarity: 0.5},
+		// Одиночная работа кластером не считается.
+		{Work1ID: 6, Work2ID: 7, Similarity: 0.1},
+		// Компонента 8-9-10 с наибольшей средней схожестью.
+		{Work1ID: 10, Work2ID: 9, Similarity: 0.95},
+		{Work1ID: 8, Work2ID: 10, Similarity: 1},
+		{Work1ID: 8, Work2ID: 9, Similarity: 0.9},
+	}
+
+	expected := []Cluster{
+		{Members: []uint64{8, 9, 10}, AvgSimilarity: 0.95, MaxSimilarity: 1},
+		{Members: []uint64{1, 2, 3}, AvgSimilarity: 0.8, MaxSimilarity: 0.9},
+		{Members: []uint64{4, 5}, AvgSimilarity: 0.5, MaxSimilarity: 0.5},
+	}
+
+	result := Build(edges, 0.5)
+	if len(result) != len(expected) {
+		t.Fatalf("Build вернул %d кластеров, ожидалось %d: %+v", len(result), len(expected), result)
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(result[i].Members, expected[i].Members) ||
+			!almostEqual(result[i].AvgSimilarity, expected[i].AvgSimilarity) ||
+			!almostEqual(result[i].MaxSimilarity, expected[i].MaxSimilarity) {
+			t.Errorf("кластер %d = %+v, ожидалось %+v", i, result[i], expected[i])
+		}
+	}
+
+	// С нулевым порогом все рёбра объединяют работы.
+	if result = Build(edges, 0); len(result) != 3 || !reflect.DeepEqual(result[1].Members, []uint64{1, 2, 3, 4, 5}) {
+		t.Errorf("Build с нулевым порогом = %+v", result)
+	}
+
+	// Без рёбер кластеров нет.
+	if result = Build(nil, 0.5); len(result) != 0 {
+		t.Errorf("Build без рёбер = %+v", result)
+	}
+}
+
+func TestExporterUpdate(t *testing.T) {
+	dir := t.TempDir()
+	exporter, err := NewExporter(dir, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = exporter.Update(7, []*checker.ReportItem{{Work1ID: 2, Work2ID: 1, Avg: 0.4}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Повторный результат по паре работ заменяет ребро, новые пары добавляются.
+	report, err := exporter.Update(7, []*checker.ReportItem{
+		{Work1ID: 1, Work2ID: 2, Avg: 0.6},
+		{Work1ID: 3, Work2ID: 2, Avg: 0.8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEdges := []Edge{{Work1ID: 1, Work2ID: 2, Similarity: 0.6}, {Work1ID: 2, Work2ID: 3, Similarity: 0.8}}
+	if !reflect.DeepEqual(report.Edges, expectedEdges) {
+		t.Errorf("рёбра = %+v, ожидалось %+v", report.Edges, expectedEdges)
+	}
+	if len(report.Clusters) != 1 || !reflect.DeepEqual(report.Clusters[0].Members, []uint64{1, 2, 3}) {
+		t.Errorf("кластеры = %+v", report.Clusters)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "7.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0133 != 0 {
+		t.Errorf("права файла отчёта = %v", perm)
+	}
+}
+
+// almostEqual сравнивает схожести с учётом погрешности вычислений.
+func almostEqual(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
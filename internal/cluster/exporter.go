@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/utils"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"strconv"
+)
+
+type Exporter interface {
+	// Update добавляет результаты анализа в граф схожести события,
+	// пересчитывает кластеры и сохраняет отчёт.
+	Update(eventID uint64, items []*checker.ReportItem) (Report, error)
+}
+
+type exporter struct {
+	dir       string
+	threshold float64
+}
+
+// NewExporter создаёт экспортёр кластеров схожих работ.
+// Dir: каталог, в котором хранятся отчёты <eventID>.json.
+// Threshold: минимальная схожесть работ внутри кластера.
+func NewExporter(dir string, threshold float64) (Exporter, error) {
+	if _, err := utils.CreateDirectory(dir); err != nil {
+		return nil, err
+	}
+
+	return &exporter{
+		dir:       dir,
+		threshold: threshold,
+	}, nil
+}
+
+// Update добавляет результаты анализа в граф схожести события,
+// пересчитывает кластеры и сохраняет отчёт.
+func (e *exporter) Update(eventID uint64, items []*checker.ReportItem) (Report, error) {
+	reportPath := path.Join(e.dir, strconv.FormatUint(eventID, 10)+".json")
+
+	// Чтение предыдущего отчёта события.
+	report, err := readReport(reportPath)
+	if err != nil {
+		return report, err
+	}
+
+	// Рёбра графа по паре работ.
+	type key struct{ work1, work2 uint64 }
+	edges := make(map[key]int, len(report.Edges)+len(items))
+	for i, edge := range report.Edges {
+		edges[key{edge.Work1ID, edge.Work2ID}] = i
+	}
+
+	// Добавление (обновление) рёбер.
+	for _, item := range items {
+		edge := Edge{
+			Work1ID:    min(item.Work1ID, item.Work2ID),
+			Work2ID:    max(item.Work1ID, item.Work2ID),
+			Similarity: item.Avg,
+		}
+
+		k := key{edge.Work1ID, edge.Work2ID}
+		if i, ok := edges[k]; ok {
+			report.Edges[i] = edge
+		} else {
+			edges[k] = len(report.Edges)
+			report.Edges = append(report.Edges, edge)
+		}
+	}
+
+	// Пересчёт кластеров.
+	report.EventID = eventID
+	report.Threshold = e.threshold
+	report.Clusters = Build(report.Edges, e.threshold)
+
+	// Сохранение отчёта.
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return report, err
+	}
+
+	return report, os.WriteFile(reportPath, data, 0644)
+}
+
+// readReport читает отчёт из файла. Если файла нет, возвращает пустой отчёт.
+func readReport(reportPath string) (Report, error) {
+	var report Report
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return report, nil
+		}
+		return report, err
+	}
+
+	err = json.Unmarshal(data, &report)
+	return report, err
+}
@@ -0,0 +1,20 @@
+package cluster
+
+type Edge struct {
+	Work1ID    uint64  `json:"work1_id"`
+	Work2ID    uint64  `json:"work2_id"`
+	Similarity float64 `json:"similarity"`
+}
+
+type Cluster struct {
+	Members       []uint64 `json:"members"`
+	AvgSimilarity float64  `json:"avg_similarity"`
+	MaxSimilarity float64  `json:"max_similarity"`
+}
+
+type Report struct {
+	EventID   uint64    `json:"event_id"`
+	Threshold float64   `json:"threshold"`
+	Clusters  []Cluster `json:"clusters"`
+	Edges     []Edge    `json:"edges"`
+}
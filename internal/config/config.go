@@ -3,16 +3,49 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 )
 
 type Config struct {
 	WorkDir        string
 	CheckerPath    string
+	CheckerLang    string
+	CheckerWorker  string
+	WorkerTimeout  uint64
+	CheckerPlugin  string
+	OffsetUnit     string
+	TabWidth       uint64
 	StorageSize    uint64
 	MainServerHost string
 	MainServerKey  string
+	MainServerTLS  bool
+	MainServerCA   string
+	MainServerName string
+	RunnerCert     string
+	RunnerKey      string
+	AuthURL        string
+	ArchiveDir     string
+	ArchiveSave    bool
+	Candidates     uint64
+	ClusterLimit   float64
+	MatchGap       uint64
+	MatchMinSize   uint64
+	ExcludeFiles   []string
+	SkipGenerated  bool
+	SkipBinary     bool
+	MaxFileSize    uint64
+	NormalizeEOL   bool
+	IgnoreDir      string
+	Notebooks      bool
+	CSharpProjects bool
+	HTMLReports    bool
+	ExportFormats  []string
+	CaptureDir     string
+	CaptureWorks   bool
+	KeepDays       uint64
 }
 
 // Заголовки переменных среды.
@@ -20,44 +53,424 @@ const (
 	envWorkDir        = "workdir"        // Путь к каталогу приложения
 	envStorageSize    = "storageSize"    // Размер папки хранилища работ в Мб.
 	envCrossCheckLib  = "checkerPath"    // Путь к библиотеке для анализа работ.
+	envCheckerLang    = "checkerLang"    // Язык анализируемых работ (по умолчанию csharp).
+	envCheckerWorker  = "checkerWorker"  // Путь к jar-архиву постоянного процесса Jplag (необязательно).
+	envWorkerTimeout  = "workerTimeout"  // Время ожидания ответа постоянного процесса Jplag в секундах (по умолчанию 600).
+	envCheckerPlugin  = "checkerPlugin"  // Путь к внешнему анализатору, используемому вместо Jplag (необязательно).
+	envOffsetUnit     = "offsetUnit"     // Единица измерения позиций в отчёте: runes, bytes, utf16 (по умолчанию runes).
+	envTabWidth       = "tabWidth"       // Ширина табуляции в столбцах анализатора (по умолчанию 1).
 	envMainServerHost = "mainServerHost" // IP адрес главного сервера
 	envMainServerKey  = "mainServerKey"  // Ключ идентификации для главного сервера.
+	envMainServerTLS  = "mainServerTls"  // Подключаться к главному серверу по TLS (true/false).
+	envMainServerCA   = "mainServerCa"   // Путь к сертификатам центров сертификации главного сервера в формате PEM (необязательно).
+	envMainServerName = "mainServerName" // Имя главного сервера для проверки сертификата, если отличается от адреса (необязательно).
+	envRunnerCert     = "runnerCert"     // Путь к сертификату раннера в формате PEM для взаимной аутентификации TLS (необязательно).
+	envRunnerKey      = "runnerKey"      // Путь к закрытому ключу сертификата раннера в формате PEM (необязательно).
+	envAuthURL        = "authUrl"        // Адрес получения краткосрочных токенов в обмен на ключ идентификации (необязательно).
+	envArchiveDir     = "archiveDir"     // Путь к архиву работ прошлых событий (необязательно).
+	envArchiveSave    = "archiveSave"    // Сохранять ли проверенные работы в архив (true/false).
+	envCandidates     = "candidates"     // Количество работ-кандидатов для сравнения с новой работой (0 - все работы).
+	envClusterLimit   = "clusterLimit"   // Минимальная схожесть работ в кластере от 0 до 1 (0 - кластеры не строятся).
+	envMatchGap       = "matchGap"       // Максимальное расстояние между объединяемыми фрагментами в единицах позиций (по умолчанию 0).
+	envMatchMinSize   = "matchMinSize"   // Минимальный размер фрагмента в единицах позиций (по умолчанию 0).
+	envExcludeFiles   = "excludeFiles"   // Шаблоны файлов, исключаемых из анализа, через запятую (необязательно).
+	envSkipGenerated  = "skipGenerated"  // Исключать автоматически сгенерированные файлы и каталоги bin/obj (true/false).
+	envSkipBinary     = "skipBinary"     // Исключать бинарные файлы (true/false).
+	envMaxFileSize    = "maxFileSize"    // Максимальный размер анализируемого файла в Кб (0 - без ограничения).
+	envNormalizeEOL   = "normalizeEol"   // Заменять переводы строк CRLF на LF перед анализом (true/false).
+	envNotebooks      = "notebooks"      // Извлекать ячейки с кодом из блокнотов Jupyter (.ipynb) перед анализом (true/false).
+	envCSharpProjects = "csharpProjects" // Анализировать только файлы C#, входящие в сборку проектов .sln/.csproj (true/false).
+	envHTMLReports    = "htmlReports"    // Сохранять отчёты HTML по парам работ в каталог приложения (true/false).
+	envExportFormats  = "exportFormats"  // Форматы файлов отчётов проверок через запятую: json, jsonl, csv, sarif (необязательно).
+	envIgnoreDir      = "ignoreDir"      // Каталог правил игнорирования файлов (.gitignore) по событиям и тегам (необязательно).
+	envCaptureDir     = "captureDir"     // Каталог пакетов воспроизведения задач для отладки (необязательно).
+	envCaptureWorks   = "captureWorks"   // Копировать работы в пакет воспроизведения, иначе сохраняются только хэши файлов (true/false).
+	envKeepDays       = "keepDays"       // Срок хранения отчётов HTML, файлов отчётов и пакетов воспроизведения в днях (0 - без ограничения).
 )
 
+// Значения по умолчанию.
+const (
+	defaultCheckerLang   = "csharp" // Язык анализируемых работ.
+	defaultOffsetUnit    = "runes"  // Единица измерения позиций в отчёте.
+	defaultWorkerTimeout = 600      // Время ожидания ответа постоянного процесса Jplag в секундах.
+)
+
+// Параметры подключения к серверу и каталог приложения: не заменяются по тегу раннера.
+var connectionKeys = []string{envWorkDir, envMainServerHost, envMainServerKey, envMainServerTLS,
+	envMainServerCA, envMainServerName, envRunnerCert, envRunnerKey, envAuthURL}
+
 var instance Config
+var readErr error // ошибка чтения переменных среды.
 var once = sync.Once{}
 
-// GetConfig читает и сохраняет переменные среды (Singleton).
+// GetConfig читает и сохраняет переменные среды (Singleton) для работы с сервером.
 func GetConfig() (Config, error) {
-	var configErr error = nil
-	isErr := true
-
-	once.Do(func() {
-		instance = Config{}
-		cacheSize, err := strconv.ParseUint(os.Getenv(envStorageSize), 10, 64)
-		if err != nil {
-			configErr = err
-			return
+	once.Do(readConfig)
+	if readErr != nil {
+		return instance, readErr
+	}
+
+	// Проверка входных параметров.
+	if os.Getenv(envStorageSize) == "" {
+		return instance, fmt.Errorf("переменная среды \"%s\" не установлена", envStorageSize)
+	}
+
+	return instance, validate(instance)
+}
+
+// GetRunnerConfig возвращает параметры для тега раннера, полученного от сервера:
+// установленная переменная среды <название>_<тег> заменяет значение параметра <название> из base.
+// Параметры подключения к серверу и каталог приложения по тегу не заменяются.
+// Если для тега переменные не заданы, возвращает base. Возвращает также названия заменённых переменных.
+func GetRunnerConfig(base Config, tag string) (Config, []string, error) {
+	if tag == "" {
+		return base, nil, nil
+	}
+
+	tagged, overrides, err := read(tag)
+	if err != nil || len(overrides) == 0 {
+		return base, nil, err
+	}
+
+	cfg := base
+	for _, name := range overrides {
+		override(&cfg, tagged, name)
+	}
+
+	return cfg, overrides, validate(cfg)
+}
+
+// override заменяет в cfg параметр переменной среды name значением из tagged.
+func override(cfg *Config, tagged Config, name string) {
+	switch name {
+	case envStorageSize:
+		cfg.StorageSize = tagged.StorageSize
+	case envCrossCheckLib:
+		cfg.CheckerPath = tagged.CheckerPath
+	case envCheckerLang:
+		cfg.CheckerLang = tagged.CheckerLang
+	case envCheckerWorker:
+		cfg.CheckerWorker = tagged.CheckerWorker
+	case envWorkerTimeout:
+		cfg.WorkerTimeout = tagged.WorkerTimeout
+	case envCheckerPlugin:
+		cfg.CheckerPlugin = tagged.CheckerPlugin
+	case envOffsetUnit:
+		cfg.OffsetUnit = tagged.OffsetUnit
+	case envTabWidth:
+		cfg.TabWidth = tagged.TabWidth
+	case envArchiveDir:
+		cfg.ArchiveDir = tagged.ArchiveDir
+	case envArchiveSave:
+		cfg.ArchiveSave = tagged.ArchiveSave
+	case envCandidates:
+		cfg.Candidates = tagged.Candidates
+	case envClusterLimit:
+		cfg.ClusterLimit = tagged.ClusterLimit
+	case envMatchGap:
+		cfg.MatchGap = tagged.MatchGap
+	case envMatchMinSize:
+		cfg.MatchMinSize = tagged.MatchMinSize
+	case envExcludeFiles:
+		cfg.ExcludeFiles = tagged.ExcludeFiles
+	case envSkipGenerated:
+		cfg.SkipGenerated = tagged.SkipGenerated
+	case envSkipBinary:
+		cfg.SkipBinary = tagged.SkipBinary
+	case envMaxFileSize:
+		cfg.MaxFileSize = tagged.MaxFileSize
+	case envNormalizeEOL:
+		cfg.NormalizeEOL = tagged.NormalizeEOL
+	case envIgnoreDir:
+		cfg.IgnoreDir = tagged.IgnoreDir
+	case envNotebooks:
+		cfg.Notebooks = tagged.Notebooks
+	case envCSharpProjects:
+		cfg.CSharpProjects = tagged.CSharpProjects
+	case envHTMLReports:
+		cfg.HTMLReports = tagged.HTMLReports
+	case envExportFormats:
+		cfg.ExportFormats = tagged.ExportFormats
+	case envCaptureDir:
+		cfg.CaptureDir = tagged.CaptureDir
+	case envCaptureWorks:
+		cfg.CaptureWorks = tagged.CaptureWorks
+	case envKeepDays:
+		cfg.KeepDays = tagged.KeepDays
+	}
+}
+
+// validate проверяет параметры для работы с сервером.
+func validate(cfg Config) error {
+	if cfg.WorkDir == "" {
+		return fmt.Errorf("переменная среды \"%s\" не установлена", envWorkDir)
+	}
+	if cfg.ArchiveSave && cfg.ArchiveDir == "" {
+		return fmt.Errorf("переменная среды \"%s\" требует \"%s\"", envArchiveSave, envArchiveDir)
+	}
+	if err := validateTLS(cfg); err != nil {
+		return err
+	}
+	return validateChecker(cfg)
+}
+
+// GetCheckConfig читает и сохраняет переменные среды (Singleton) для автономной проверки.
+// Адрес и ключ сервера, размер хранилища и каталог приложения не требуются.
+func GetCheckConfig() (Config, error) {
+	once.Do(readConfig)
+	if readErr != nil {
+		return instance, readErr
+	}
+
+	return instance, validateChecker(instance)
+}
+
+// readConfig читает переменные среды.
+func readConfig() {
+	instance, _, readErr = read("")
+}
+
+// read читает переменные среды. Если указан тег раннера, установленная переменная <название>_<тег>
+// заменяет переменную <название> (кроме параметров подключения к серверу и каталога приложения).
+// Возвращает также названия заменённых переменных.
+func read(tag string) (Config, []string, error) {
+	cfg := Config{}
+	var overrides []string
+	key := func(name string) string {
+		if tag == "" || slices.Contains(connectionKeys, name) {
+			return name
+		}
+		if _, ok := os.LookupEnv(name + "_" + tag); ok {
+			overrides = append(overrides, name)
+			return name + "_" + tag
+		}
+		return name
+	}
+
+	cacheSize, err := getEnvUint(key(envStorageSize))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	archiveSave, err := getEnvBool(key(envArchiveSave))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	candidates, err := getEnvUint(key(envCandidates))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	clusterLimit, err := getEnvFloat(key(envClusterLimit))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	tabWidth, err := getEnvUint(key(envTabWidth))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	workerTimeout, err := getEnvUint(key(envWorkerTimeout))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	matchGap, err := getEnvUint(key(envMatchGap))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	matchMinSize, err := getEnvUint(key(envMatchMinSize))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	skipGenerated, err := getEnvBool(key(envSkipGenerated))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	skipBinary, err := getEnvBool(key(envSkipBinary))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	maxFileSize, err := getEnvUint(key(envMaxFileSize))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	normalizeEOL, err := getEnvBool(key(envNormalizeEOL))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	notebooks, err := getEnvBool(key(envNotebooks))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	csharpProjects, err := getEnvBool(key(envCSharpProjects))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	htmlReports, err := getEnvBool(key(envHTMLReports))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	captureWorks, err := getEnvBool(key(envCaptureWorks))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	keepDays, err := getEnvUint(key(envKeepDays))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	mainServerTLS, err := getEnvBool(key(envMainServerTLS))
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	cfg.WorkDir = os.Getenv(key(envWorkDir))
+	cfg.CheckerPath = os.Getenv(key(envCrossCheckLib))
+	cfg.CheckerLang = os.Getenv(key(envCheckerLang))
+	cfg.CheckerWorker = os.Getenv(key(envCheckerWorker))
+	cfg.WorkerTimeout = workerTimeout
+	cfg.CheckerPlugin = os.Getenv(key(envCheckerPlugin))
+	cfg.OffsetUnit = os.Getenv(key(envOffsetUnit))
+	cfg.TabWidth = tabWidth
+	cfg.MainServerHost = os.Getenv(key(envMainServerHost))
+	cfg.MainServerKey = os.Getenv(key(envMainServerKey))
+	cfg.MainServerTLS = mainServerTLS
+	cfg.MainServerCA = os.Getenv(key(envMainServerCA))
+	cfg.MainServerName = os.Getenv(key(envMainServerName))
+	cfg.RunnerCert = os.Getenv(key(envRunnerCert))
+	cfg.RunnerKey = os.Getenv(key(envRunnerKey))
+	cfg.AuthURL = os.Getenv(key(envAuthURL))
+	cfg.StorageSize = cacheSize
+	cfg.ArchiveDir = os.Getenv(key(envArchiveDir))
+	cfg.ArchiveSave = archiveSave
+	cfg.Candidates = candidates
+	cfg.ClusterLimit = clusterLimit
+	cfg.MatchGap = matchGap
+	cfg.MatchMinSize = matchMinSize
+	cfg.ExcludeFiles = getEnvList(key(envExcludeFiles))
+	cfg.SkipGenerated = skipGenerated
+	cfg.SkipBinary = skipBinary
+	cfg.MaxFileSize = maxFileSize
+	cfg.NormalizeEOL = normalizeEOL
+	cfg.IgnoreDir = os.Getenv(key(envIgnoreDir))
+	cfg.Notebooks = notebooks
+	cfg.CSharpProjects = csharpProjects
+	cfg.HTMLReports = htmlReports
+	cfg.ExportFormats = getEnvList(key(envExportFormats))
+	cfg.CaptureDir = os.Getenv(key(envCaptureDir))
+	cfg.CaptureWorks = captureWorks
+	cfg.KeepDays = keepDays
+
+	// Значения по умолчанию.
+	if cfg.CheckerLang == "" {
+		cfg.CheckerLang = defaultCheckerLang
+	}
+	if cfg.OffsetUnit == "" {
+		cfg.OffsetUnit = defaultOffsetUnit
+	}
+	if cfg.TabWidth == 0 {
+		cfg.TabWidth = 1
+	}
+	if cfg.WorkerTimeout == 0 {
+		cfg.WorkerTimeout = defaultWorkerTimeout
+	}
+
+	return cfg, overrides, nil
+}
+
+// validateChecker проверяет параметры анализа.
+func validateChecker(cfg Config) error {
+	if cfg.CheckerPath == "" && cfg.CheckerPlugin == "" {
+		return fmt.Errorf("переменная среды \"%s\" не установлена", envCrossCheckLib)
+	}
+	if cfg.ClusterLimit < 0 || cfg.ClusterLimit > 1 {
+		return fmt.Errorf("переменная среды \"%s\" должна быть от 0 до 1", envClusterLimit)
+	}
+	return nil
+}
+
+// validateTLS проверяет параметры TLS подключения к главному серверу.
+func validateTLS(cfg Config) error {
+	if !cfg.MainServerTLS {
+		keys := []string{envMainServerCA, envMainServerName, envRunnerCert, envRunnerKey}
+		values := []string{cfg.MainServerCA, cfg.MainServerName, cfg.RunnerCert, cfg.RunnerKey}
+		for i, value := range values {
+			if value != "" {
+				return fmt.Errorf("переменная среды \"%s\" требует \"%s=true\"", keys[i], envMainServerTLS)
+			}
 		}
+	}
+	if (cfg.RunnerCert == "") != (cfg.RunnerKey == "") {
+		return fmt.Errorf("переменные среды \"%s\" и \"%s\" задаются вместе", envRunnerCert, envRunnerKey)
+	}
+	return nil
+}
+
+// getEnvBool читает логическую переменную среды. Если переменная не установлена, возвращает false.
+func getEnvBool(key string) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return false, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("переменная среды \"%s\": %v", key, err)
+	}
 
-		instance.WorkDir = os.Getenv(envWorkDir)
-		instance.CheckerPath = os.Getenv(envCrossCheckLib)
-		instance.MainServerHost = os.Getenv(envMainServerHost)
-		instance.MainServerKey = os.Getenv(envMainServerKey)
-		instance.StorageSize = cacheSize
-
-		// Проверка входных параметров.
-		if instance.WorkDir == "" {
-			err = fmt.Errorf("переменная среды \"%s\" не установлена", envWorkDir)
-		} else if instance.CheckerPath == "" {
-			err = fmt.Errorf("переменная среды \"%s\" не установлена", envCrossCheckLib)
-		} else {
-			isErr = false
+	return result, nil
+}
+
+// getEnvList читает переменную среды со списком значений через запятую. Пустые значения пропускаются.
+func getEnvList(key string) []string {
+	var result []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
 		}
-	})
+	}
+	return result
+}
 
-	if isErr {
-		return instance, configErr
+// getEnvUint читает целочисленную переменную среды. Если переменная не установлена, возвращает 0.
+func getEnvUint(key string) (uint64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
 	}
-	return instance, nil
+
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("переменная среды \"%s\": %v", key, err)
+	}
+
+	return result, nil
+}
+
+// getEnvFloat читает вещественную переменную среды. Если переменная не установлена, возвращает 0.
+func getEnvFloat(key string) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("переменная среды \"%s\": %v", key, err)
+	}
+
+	return result, nil
 }
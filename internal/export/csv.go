@@ -0,0 +1,48 @@
+package export
+
+import (
+	"CodeBorrowing/internal/checker"
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// Столбцы таблицы схожести.
+var csvHeader = []string{
+	"schema",
+	"work1_id", "work1_name", "work1_event_id",
+	"work2_id", "work2_name", "work2_event_id",
+	"avg", "max", "matches", "work1_matched", "work2_matched", "offset_unit",
+}
+
+// writeCSV записывает таблицу схожести пар работ.
+// Work1_matched, work2_matched - суммарный размер фрагментов совпадений в единицах offset_unit.
+func writeCSV(w io.Writer, items []*checker.ReportItem) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		var matched1, matched2 uint64
+		for _, m := range item.Matches {
+			matched1 += m.Work1Size
+			matched2 += m.Work2Size
+		}
+
+		err := writer.Write([]string{
+			strconv.Itoa(SchemaVersion),
+			strconv.FormatUint(item.Work1ID, 10), item.Work1Name, strconv.FormatUint(item.Work1EventID, 10),
+			strconv.FormatUint(item.Work2ID, 10), item.Work2Name, strconv.FormatUint(item.Work2EventID, 10),
+			strconv.FormatFloat(item.Avg, 'f', -1, 64), strconv.FormatFloat(item.Max, 'f', -1, 64),
+			strconv.Itoa(len(item.Matches)), strconv.FormatUint(matched1, 10), strconv.FormatUint(matched2, 10),
+			string(item.OffsetUnit),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"CodeBorrowing/internal/checker"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SchemaVersion версия схемы экспортируемых отчётов.
+// Увеличивается при несовместимых изменениях полей отчётов.
+const SchemaVersion = 1
+
+// Format - формат экспорта отчёта.
+type Format string
+
+const (
+	FormatJSON  Format = "json"  // отчёт целиком: сведения о запуске и пары работ.
+	FormatJSONL Format = "jsonl" // JSON Lines: пара работ в строке.
+	FormatCSV   Format = "csv"   // таблица схожести пар работ.
+	FormatSARIF Format = "sarif" // SARIF 2.1.0: совпадения как результаты анализа кода.
+)
+
+// Report - отчёт проверки в формате json.
+type Report struct {
+	Schema int                   `json:"schema"`
+	Info   checker.RunInfo       `json:"info"`
+	Items  []*checker.ReportItem `json:"items"`
+}
+
+// Record - строка отчёта в формате jsonl.
+type Record struct {
+	Schema int `json:"schema"`
+	*checker.ReportItem
+}
+
+// ParseFormat читает формат экспорта.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case FormatJSON, FormatJSONL, FormatCSV, FormatSARIF:
+		return format, nil
+	default:
+		return "", fmt.Errorf("неизвестный формат отчёта \"%s\"", s)
+	}
+}
+
+// Ext возвращает расширение файла формата.
+func (f Format) Ext() string {
+	if f == FormatSARIF {
+		return ".sarif.json"
+	}
+	return "." + string(f)
+}
+
+// Write записывает результат проверки в формате format.
+// Info: сведения о запуске анализа. Файлы работ читаются из каталогов item.Work1Dir и item.Work2Dir (для SARIF).
+func Write(w io.Writer, format Format, info checker.RunInfo, items []*checker.ReportItem) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(Report{Schema: SchemaVersion, Info: info, Items: items})
+	case FormatJSONL:
+		encoder := json.NewEncoder(w)
+		for _, item := range items {
+			if err := encoder.Encode(Record{Schema: SchemaVersion, ReportItem: item}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		return writeCSV(w, items)
+	case FormatSARIF:
+		return writeSARIF(w, info, items)
+	default:
+		return fmt.Errorf("неизвестный формат отчёта \"%s\"", format)
+	}
+}
@@ -0,0 +1,208 @@
+package export
+
+import (
+	"CodeBorrowing/internal/checker"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatJSONL, FormatCSV, FormatSARIF} {
+		if parsed, err := ParseFormat(string(format)); err != nil || parsed != format {
+			t.Errorf("ParseFormat(%q) = %q, %v", format, parsed, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(\"xml\"): ожидалась ошибка")
+	}
+
+	if ext := FormatSARIF.Ext(); ext != ".sarif.json" {
+		t.Errorf("Ext = %q", ext)
+	}
+	if ext := FormatJSONL.Ext(); ext != ".jsonl" {
+		t.Errorf("Ext = %q", ext)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	item := testItem(t)
+	info := checker.RunInfo{Language: "csharp", TotalComparisons: 1}
+
+	sb := strings.Builder{}
+	if err := Write(&sb, FormatJSON, info, []*checker.ReportItem{item}); err != nil {
+		t.Fatal(err)
+	}
+
+	var report Report
+	if err := json.Unmarshal([]byte(sb.String()), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Schema != SchemaVersion || report.Info.Language != "csharp" || len(report.Items) != 1 {
+		t.Fatalf("отчёт = %+v", report)
+	}
+
+	// Каталоги работ не экспортируются.
+	item.Work1Dir, item.Work2Dir = "", ""
+	if !reflect.DeepEqual(report.Items[0], item) {
+		t.Errorf("пара работ = %+v, ожидалось %+v", report.Items[0], item)
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	items := []*checker.ReportItem{testItem(t), {Work1Name: "a", Work2Name: "b", OffsetUnit: checker.UnitBytes}}
+
+	sb := strings.Builder{}
+	if err := Write(&sb, FormatJSONL, checker.RunInfo{}, items); err != nil {
+		t.Fatal(err)
+	}
+
+	// Пара работ в строке с версией схемы.
+	var lines []map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(sb.String()))
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("строк %d, ожидалось 2", len(lines))
+	}
+	if lines[0]["schema"] != float64(SchemaVersion) || lines[0]["work1_id"] != float64(101) || lines[1]["work2_name"] != "b" {
+		t.Errorf("строки = %v", lines)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	item := testItem(t)
+	item.Work1Name = "Иванов, группа 1"
+
+	sb := strings.Builder{}
+	if err := Write(&sb, FormatCSV, checker.RunInfo{}, []*checker.ReportItem{item}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		csvHeader,
+		{"1", "101", "Иванов, группа 1", "7", "102", "", "8", "0.625", "0.75", "2", "17", "17", "runes"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("таблица = %v, ожидалось %v", records, expected)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	item := testItem(t)
+	writeWorkFile(t, item.Work1Dir, "Emoji.cs", "x😀y\n")
+	writeWorkFile(t, item.Work2Dir, "Emoji.cs", "y\n")
+	item.Matches = append(item.Matches, checker.MatchItem{
+		Work1File: "Emoji.cs", Work1Start: 2, Work1Size: 1,
+		Work2File: "Emoji.cs", Work2Start: 0, Work2Size: 1,
+	})
+
+	sb := strings.Builder{}
+	if err := Write(&sb, FormatSARIF, checker.RunInfo{Language: "csharp"}, []*checker.ReportItem{item}); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(sb.String()), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("журнал = %+v", log)
+	}
+
+	// По два результата на совпадение: по фрагменту каждой работы.
+	run := log.Runs[0]
+	if len(run.Results) != 6 {
+		t.Fatalf("результатов %d, ожидалось 6", len(run.Results))
+	}
+	for _, base := range []string{"WORK_101", "WORK_102"} {
+		if !strings.HasPrefix(run.OriginalURIBaseIDs[base].URI, "file://") {
+			t.Errorf("каталог %s = %q", base, run.OriginalURIBaseIDs[base].URI)
+		}
+	}
+
+	// Области в строках и столбцах UTF-16, конечный столбец не входит в область.
+	regions := []sarifRegion{
+		{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 11},
+		{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 11},
+		{StartLine: 3, StartColumn: 2, EndLine: 3, EndColumn: 9},
+		{StartLine: 4, StartColumn: 2, EndLine: 4, EndColumn: 9},
+		{StartLine: 1, StartColumn: 4, EndLine: 1, EndColumn: 5},
+		{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 2},
+	}
+	for i, result := range run.Results {
+		loc := result.Locations[0].PhysicalLocation
+		if loc.Region == nil || *loc.Region != regions[i] {
+			t.Errorf("результат %d: область %+v, ожидалось %+v", i, loc.Region, regions[i])
+		}
+
+		// Ссылка на парный фрагмент другой работы.
+		peer := result.RelatedLocations[0].PhysicalLocation
+		if loc.ArtifactLocation.URIBaseID == peer.ArtifactLocation.URIBaseID {
+			t.Errorf("результат %d: парный фрагмент в той же работе", i)
+		}
+	}
+}
+
+// TestWriteSARIFSameNames проверяет, что работы с одинаковыми названиями из разных каталогов
+// получают разные идентификаторы каталогов, а каталог одной работы добавляется один раз.
+func TestWriteSARIFSameNames(t *testing.T) {
+	root := t.TempDir()
+	newAlice, oldAlice, newBob := path.Join(root, "new", "alice"), path.Join(root, "old", "alice"), path.Join(root, "new", "bob")
+	for _, dir := range []string{newAlice, oldAlice, newBob} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeWorkFile(t, dir, "Main.cs", "class Main {}\n")
+	}
+
+	match := []checker.MatchItem{{Work1File: "Main.cs", Work1Start: 0, Work1Size: 5, Work2File: "Main.cs", Work2Start: 0, Work2Size: 5}}
+	items := []*checker.ReportItem{
+		{Work1Name: "alice", Work2Name: "alice", Work1Dir: newAlice, Work2Dir: oldAlice, OffsetUnit: checker.UnitRunes, Matches: match},
+		{Work1Name: "bob", Work2Name: "alice", Work1Dir: newBob, Work2Dir: oldAlice, OffsetUnit: checker.UnitRunes, Matches: match},
+	}
+
+	sb := strings.Builder{}
+	if err := Write(&sb, FormatSARIF, checker.RunInfo{}, items); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal([]byte(sb.String()), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+
+	expected := map[string]string{"WORK_alice": newAlice, "WORK_alice_2": oldAlice, "WORK_bob": newBob}
+	if len(run.OriginalURIBaseIDs) != len(expected) {
+		t.Errorf("каталоги работ %v, ожидалось %v", run.OriginalURIBaseIDs, expected)
+	}
+	for base, dir := range expected {
+		if uri := run.OriginalURIBaseIDs[base].URI; !strings.HasSuffix(uri, dir+"/") {
+			t.Errorf("каталог %s = %q, ожидалось %s", base, uri, dir)
+		}
+	}
+
+	// Фрагменты указывают на каталоги своих работ.
+	bases := []string{"WORK_alice", "WORK_alice_2", "WORK_bob", "WORK_alice_2"}
+	for i, result := range run.Results {
+		if base := result.Locations[0].PhysicalLocation.ArtifactLocation.URIBaseID; base != bases[i] {
+			t.Errorf("результат %d: каталог %s, ожидалось %s", i, base, bases[i])
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package export
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/preprocess"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Количество цветов выделения фрагментов.
+const colors = 8
+
+// Символы, недопустимые в названии файла отчёта.
+var unsafeNameRe = regexp.MustCompile(`[^\p{L}\p{N}_.-]+`)
+
+// htmlPage - данные страницы отчёта.
+type htmlPage struct {
+	Item    *checker.ReportItem
+	Work1   string
+	Work2   string
+	Avg     string
+	Max     string
+	Matches []htmlMatch
+	Pairs   []htmlPair
+}
+
+// htmlMatch - строка таблицы совпадений.
+type htmlMatch struct {
+	ID     int
+	File1  string
+	Lines1 string
+	File2  string
+	Lines2 string
+}
+
+// htmlPair - пара файлов с совпадениями.
+type htmlPair struct {
+	File1 string
+	File2 string
+	Code1 template.HTML
+	Code2 template.HTML
+}
+
+// fragment - фрагмент совпадения в отображаемом тексте файла.
+type fragment struct {
+	id   int    // номер совпадения.
+	from int    // байтовая позиция начала.
+	to   int    // байтовая позиция конца.
+	self string // якорь фрагмента.
+	peer string // якорь парного фрагмента.
+}
+
+// fileView - отображаемый текст файла работы.
+type fileView struct {
+	text  []byte
+	lines *checker.LineIndex
+	cells map[uint32]cellView // ячейки блокнота по номеру.
+	err   error
+}
+
+// cellView - ячейка блокнота в отображаемом тексте.
+type cellView struct {
+	start int
+	lines *checker.LineIndex
+}
+
+// WriteHTML записывает отчёт о совпадениях пары работ в виде самодостаточной страницы HTML:
+// файлы работ рядом, выделенные и связанные ссылками фрагменты, оценки схожести.
+// Файлы читаются из каталогов работ item.Work1Dir и item.Work2Dir.
+func WriteHTML(w io.Writer, item *checker.ReportItem) error {
+	page := htmlPage{
+		Item:  item,
+		Work1: workTitle(item.Work1ID, item.Work1Name),
+		Work2: workTitle(item.Work2ID, item.Work2Name),
+		Avg:   fmt.Sprintf("%.1f%%", item.Avg*100),
+		Max:   fmt.Sprintf("%.1f%%", item.Max*100),
+	}
+
+	views := make(map[string]*fileView)
+	view := func(dir string, file string) *fileView {
+		key := path.Join(dir, file)
+		if v, ok := views[key]; ok {
+			return v
+		}
+		v := readFileView(dir, file)
+		views[key] = v
+		return v
+	}
+
+	// Группировка совпадений по паре файлов в порядке появления.
+	type filePair struct{ file1, file2 string }
+	var order []filePair
+	fragments := make(map[filePair][2][]fragment)
+	for i, m := range item.Matches {
+		id := i + 1
+		key := filePair{m.Work1File, m.Work2File}
+		if _, ok := fragments[key]; !ok {
+			order = append(order, key)
+		}
+
+		v1, v2 := view(item.Work1Dir, m.Work1File), view(item.Work2Dir, m.Work2File)
+		f1 := v1.fragment(m.Work1Start, m.Work1Size, m.Work1Cell, item.OffsetUnit)
+		f2 := v2.fragment(m.Work2Start, m.Work2Size, m.Work2Cell, item.OffsetUnit)
+		f1.id, f1.self, f1.peer = id, fmt.Sprintf("m%d-1", id), fmt.Sprintf("m%d-2", id)
+		f2.id, f2.self, f2.peer = id, f1.peer, f1.self
+
+		pair := fragments[key]
+		pair[0], pair[1] = append(pair[0], f1), append(pair[1], f2)
+		fragments[key] = pair
+
+		page.Matches = append(page.Matches, htmlMatch{
+			ID:     id,
+			File1:  m.Work1File,
+			Lines1: v1.lineRange(f1),
+			File2:  m.Work2File,
+			Lines2: v2.lineRange(f2),
+		})
+	}
+
+	for _, key := range order {
+		pair := fragments[key]
+		page.Pairs = append(page.Pairs, htmlPair{
+			File1: key.file1,
+			File2: key.file2,
+			Code1: view(item.Work1Dir, key.file1).render(pair[0]),
+			Code2: view(item.Work2Dir, key.file2).render(pair[1]),
+		})
+	}
+
+	return pageTemplate.Execute(w, page)
+}
+
+// SaveHTML сохраняет отчёты о совпадениях пар работ в каталог dir, по файлу на пару работ.
+// Если названия файлов пар совпадают, к названию добавляется номер: <work1>_<work2>_2.html.
+func SaveHTML(dir string, items []*checker.ReportItem) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	used := make(map[string]bool, len(items))
+	for _, item := range items {
+		name := HTMLFileName(item)
+		base := strings.TrimSuffix(name, ".html")
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d.html", base, n)
+		}
+		used[name] = true
+
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		err = WriteHTML(f, item)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HTMLFileName возвращает название файла отчёта пары работ: id работ или их названия без недопустимых символов.
+// Если от названия ничего не осталось, используется хэш названия.
+func HTMLFileName(item *checker.ReportItem) string {
+	name := func(id uint64, name string) string {
+		if id != 0 {
+			return strconv.FormatUint(id, 10)
+		}
+		if safe := strings.Trim(unsafeNameRe.ReplaceAllString(name, "_"), "._"); safe != "" {
+			return safe
+		}
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(name))
+		return fmt.Sprintf("%08x", h.Sum32())
+	}
+	return name(item.Work1ID, item.Work1Name) + "_" + name(item.Work2ID, item.Work2Name) + ".html"
+}
+
+// workTitle возвращает название работы для отображения.
+func workTitle(id uint64, name string) string {
+	if id != 0 {
+		return fmt.Sprintf("работа %d", id)
+	}
+	return name
+}
+
+// readFileView читает отображаемый текст файла работы.
+// Для блокнота Jupyter отображаются ячейки с кодом с заголовками.
+func readFileView(dir string, file string) *fileView {
+	content, err := os.ReadFile(path.Join(dir, file))
+	if err != nil {
+		return &fileView{err: err, lines: checker.NewLineIndex(nil)}
+	}
+
+	if !preprocess.IsNotebook(file) {
+		return &fileView{text: content, lines: checker.NewLineIndex(content)}
+	}
+
+	cells, err := preprocess.ReadNotebook(content)
+	if err != nil {
+		return &fileView{err: err, lines: checker.NewLineIndex(nil)}
+	}
+
+	v := &fileView{cells: make(map[uint32]cellView, len(cells))}
+	for _, c := range cells {
+		v.text = append(v.text, fmt.Sprintf("# ---- ячейка %d ----\n", c.Index)...)
+		v.cells[c.Index] = cellView{start: len(v.text), lines: checker.NewLineIndex([]byte(c.Source))}
+		v.text = append(v.text, c.Source...)
+		v.text = append(v.text, "\n\n"...)
+	}
+	v.lines = checker.NewLineIndex(v.text)
+	return v
+}
+
+// fragment переводит позицию фрагмента в байтовые позиции отображаемого текста.
+func (v *fileView) fragment(start uint64, size uint64, cell *uint32, unit checker.OffsetUnit) fragment {
+	if v.err != nil {
+		return fragment{}
+	}
+
+	if cell != nil {
+		c, ok := v.cells[*cell]
+		if !ok {
+			return fragment{}
+		}
+		return fragment{from: c.start + c.lines.FromUnit(start, unit), to: c.start + c.lines.FromUnit(start+size, unit)}
+	}
+
+	return fragment{from: v.lines.FromUnit(start, unit), to: v.lines.FromUnit(start+size, unit)}
+}
+
+// lineRange возвращает строки фрагмента для таблицы совпадений.
+func (v *fileView) lineRange(f fragment) string {
+	if v.err != nil {
+		return "-"
+	}
+
+	first, last := v.lines.LineOf(f.from), v.lines.LineOf(max(f.to-1, f.from))
+	if first == last {
+		return strconv.Itoa(first)
+	}
+	return fmt.Sprintf("%d-%d", first, last)
+}
+
+// render формирует HTML текста файла с выделенными фрагментами. Каждая строка - элемент span.l.
+func (v *fileView) render(fragments []fragment) template.HTML {
+	if v.err != nil {
+		return template.HTML(`<span class="err">` + html.EscapeString(v.err.Error()) + `</span>`)
+	}
+
+	// Границы частей текста: начала и концы фрагментов.
+	bounds := []int{len(v.text)}
+	for _, f := range fragments {
+		bounds = append(bounds, f.from, f.to)
+	}
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+
+	sb := strings.Builder{}
+	for line := 1; line <= v.lines.Lines(); line++ {
+		start := v.lines.LineStart(line)
+		end := len(v.text)
+		if line < v.lines.Lines() {
+			end = v.lines.LineStart(line+1) - 1
+		}
+		if line == v.lines.Lines() && start == end && line > 1 {
+			break // пустая строка после завершающего перевода строки.
+		}
+
+		sb.WriteString(`<span class="l">`)
+		for pos := start; pos <= end; {
+			// Якоря фрагментов, начинающихся в позиции.
+			for _, f := range fragments {
+				if f.from == pos && f.self != "" {
+					sb.WriteString(`<span id="` + f.self + `"></span>`)
+				}
+			}
+			if pos == end {
+				break
+			}
+
+			// Часть текста до следующей границы.
+			next := end
+			if i, _ := slices.BinarySearch(bounds, pos+1); i < len(bounds) && bounds[i] < end {
+				next = bounds[i]
+			}
+			text := html.EscapeString(strings.TrimRight(string(v.text[pos:next]), "\r"))
+			if text == "" {
+				pos = next
+				continue
+			}
+
+			// Первый фрагмент, содержащий часть текста.
+			covering := -1
+			for i, f := range fragments {
+				if f.from <= pos && pos < f.to {
+					covering = i
+					break
+				}
+			}
+
+			if covering == -1 {
+				sb.WriteString(text)
+			} else {
+				f := fragments[covering]
+				fmt.Fprintf(&sb, `<a class="m c%d" href="#%s" title="совпадение %d">%s</a>`,
+					(f.id-1)%colors, f.peer, f.id, text)
+			}
+			pos = next
+		}
+		sb.WriteString("</span>\n")
+	}
+
+	return template.HTML(sb.String())
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Work1}} / {{.Work2}}</title>
+<style>
+body { font-family: sans-serif; margin: 16px; }
+table { border-collapse: collapse; margin-bottom: 16px; }
+td, th { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+.pair { display: flex; gap: 8px; margin-bottom: 24px; }
+.pane { flex: 1; min-width: 0; }
+.name { font-weight: bold; margin: 4px 0; }
+pre { margin: 0; max-height: 80vh; overflow: auto; border: 1px solid #ccc; counter-reset: line; font-size: 13px; }
+.l { display: block; min-height: 1.2em; }
+.l::before { counter-increment: line; content: counter(line); display: inline-block; width: 4em; margin-right: 8px;
+  color: #999; text-align: right; user-select: none; }
+.m { color: inherit; text-decoration: none; }
+.m:target, :target + .m { outline: 2px solid #000; }
+.c0 { background: #fdd; } .c1 { background: #dfd; } .c2 { background: #ddf; } .c3 { background: #ffd; }
+.c4 { background: #fdf; } .c5 { background: #dff; } .c6 { background: #fed; } .c7 { background: #def; }
+.err { color: #c00; }
+</style>
+</head>
+<body>
+<h1>{{.Work1}} / {{.Work2}}</h1>
+<table>
+<tr><th>Средняя схожесть</th><td>{{.Avg}}</td></tr>
+<tr><th>Максимальная схожесть</th><td>{{.Max}}</td></tr>
+<tr><th>События</th><td>{{.Item.Work1EventID}} / {{.Item.Work2EventID}}</td></tr>
+<tr><th>Совпадений</th><td>{{len .Matches}}</td></tr>
+</table>
+<table>
+<tr><th>#</th><th>{{.Work1}}</th><th>Строки</th><th>{{.Work2}}</th><th>Строки</th></tr>
+{{range .Matches}}<tr><td><a href="#m{{.ID}}-1">{{.ID}}</a></td><td>{{.File1}}</td><td>{{.Lines1}}</td><td>{{.File2}}</td><td>{{.Lines2}}</td></tr>
+{{end}}</table>
+{{range .Pairs}}<div class="pair">
+<div class="pane"><div class="name">{{$.Work1}}: {{.File1}}</div><pre>{{.Code1}}</pre></div>
+<div class="pane"><div class="name">{{$.Work2}}: {{.File2}}</div><pre>{{.Code2}}</pre></div>
+</div>
+{{end}}</body>
+</html>
+`))
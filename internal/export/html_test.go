@@ -0,0 +1,139 @@
+package export
+
+import (
+	"CodeBorrowing/internal/checker"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWorkFile создаёт файл работы в каталоге dir.
+func writeWorkFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testItem возвращает пару работ с двумя совпадениями в файлах Main.cs.
+func testItem(t *testing.T) *checker.ReportItem {
+	t.Helper()
+
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	writeWorkFile(t, dir1, "Main.cs", "if (a < b)\r\n{\r\n\treturn;\r\n}\r\n")
+	writeWorkFile(t, dir2, "Main.cs", "// копия\nif (a < b)\n{\n\treturn;\n}\n")
+
+	return &checker.ReportItem{
+		Work1ID:      101,
+		Work2ID:      102,
+		Work1Dir:     dir1,
+		Work2Dir:     dir2,
+		Work1EventID: 7,
+		Work2EventID: 8,
+		Avg:          0.625,
+		Max:          0.75,
+		OffsetUnit:   checker.UnitRunes,
+		Matches: []checker.MatchItem{
+			{Work1File: "Main.cs", Work1Start: 0, Work1Size: 10, Work2File: "Main.cs", Work2Start: 9, Work2Size: 10},
+			{Work1File: "Main.cs", Work1Start: 16, Work1Size: 7, Work2File: "Main.cs", Work2Start: 23, Work2Size: 7},
+		},
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	item := testItem(t)
+
+	sb := strings.Builder{}
+	if err := WriteHTML(&sb, item); err != nil {
+		t.Fatal(err)
+	}
+	page := sb.String()
+
+	expected := []string{
+		// Названия работ и оценки схожести.
+		"<title>работа 101 / работа 102</title>",
+		"62.5%",
+		"75.0%",
+		// Фрагменты выделены, экранированы и ссылаются на парные фрагменты.
+		`<span id="m1-1"></span><a class="m c0" href="#m1-2" title="совпадение 1">if (a &lt; b)</a>`,
+		`<span id="m1-2"></span><a class="m c0" href="#m1-1" title="совпадение 1">if (a &lt; b)</a>`,
+		`<a class="m c1" href="#m2-2" title="совпадение 2">return;</a>`,
+		// Строка вне совпадений выводится без выделения.
+		`<span class="l">// копия</span>`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(page, s) {
+			t.Errorf("страница не содержит %q", s)
+		}
+	}
+
+	// Символы \r не выводятся.
+	if strings.Contains(page, "\r") {
+		t.Error("страница содержит \\r")
+	}
+}
+
+func TestWriteHTMLMissingFile(t *testing.T) {
+	item := testItem(t)
+	item.Matches[0].Work2File = "Missing.cs"
+
+	sb := strings.Builder{}
+	if err := WriteHTML(&sb, item); err != nil {
+		t.Fatal(err)
+	}
+
+	// Недоступный файл отображается сообщением об ошибке, остальные файлы - как обычно.
+	if !strings.Contains(sb.String(), `<span class="err">`) {
+		t.Error("страница не содержит сообщение об ошибке чтения файла")
+	}
+	if !strings.Contains(sb.String(), `href="#m2-2"`) {
+		t.Error("страница не содержит второе совпадение")
+	}
+}
+
+func TestSaveHTML(t *testing.T) {
+	item := testItem(t)
+	items := []*checker.ReportItem{
+		item,
+		{Work1Name: "Иванов И.И.", Work2Name: "Петров П.П.", OffsetUnit: checker.UnitRunes},
+		{Work1Name: "Иванов И.И.", Work2Name: "Сидоров С.С.", OffsetUnit: checker.UnitRunes},
+		// Названия, совпадающие после замены недопустимых символов.
+		{Work1Name: "new/alice", Work2Name: "bob", OffsetUnit: checker.UnitRunes},
+		{Work1Name: "new alice", Work2Name: "bob", OffsetUnit: checker.UnitRunes},
+		// Названия без допустимых символов.
+		{Work1Name: "..", Work2Name: "/", OffsetUnit: checker.UnitRunes},
+	}
+
+	dir := filepath.Join(t.TempDir(), "reports", "7")
+	if err := SaveHTML(dir, items); err != nil {
+		t.Fatal(err)
+	}
+
+	// Файл на каждую пару работ, названия не выходят за пределы каталога.
+	expected := []string{
+		"101_102.html",
+		"Иванов_И.И_Петров_П.П.html",
+		"Иванов_И.И_Сидоров_С.С.html",
+		"new_alice_bob.html",
+		"new_alice_bob_2.html",
+		HTMLFileName(items[5]),
+	}
+	for _, name := range expected {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(items) {
+		t.Errorf("файлов %d, ожидалось %d", len(entries), len(items))
+	}
+
+	if name := HTMLFileName(items[5]); strings.HasPrefix(name, "_") || strings.Contains(name, "..") {
+		t.Errorf("HTMLFileName = %q", name)
+	}
+}
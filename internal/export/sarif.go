@@ -0,0 +1,242 @@
+package export
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/preprocess"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifTool    = "CodeBorrowing"
+	sarifRuleID  = "CB0001"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifToolDTO                     `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+	Properties         map[string]any                   `json:"properties,omitempty"`
+}
+
+type sarifToolDTO struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	RelatedLocations    []sarifLocation   `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// sarifRegion - область файла. Столбцы в единицах UTF-16 (по умолчанию в SARIF), конечный столбец не входит в область.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// sarifSide - сторона совпадения.
+type sarifSide struct {
+	title string
+	base  string
+	file  string
+	start uint64
+	size  uint64
+	cell  *uint32
+}
+
+// writeSARIF записывает совпадения в формате SARIF 2.1.0.
+// Для каждого совпадения формируются два результата: по файлу каждой работы со ссылкой на парный фрагмент.
+// Пути к файлам указываются относительно каталогов работ (originalUriBaseIds).
+func writeSARIF(w io.Writer, info checker.RunInfo, items []*checker.ReportItem) error {
+	run := sarifRun{
+		Tool: sarifToolDTO{Driver: sarifDriver{
+			Name: sarifTool,
+			Rules: []sarifRule{{
+				ID:               sarifRuleID,
+				Name:             "CodeBorrowing",
+				ShortDescription: sarifMessage{Text: "Фрагмент кода совпадает с фрагментом другой работы"},
+			}},
+		}},
+		OriginalURIBaseIDs: make(map[string]sarifArtifactLocation),
+		Results:            []sarifResult{},
+		Properties: map[string]any{
+			"schema":         SchemaVersion,
+			"checkerVersion": info.CheckerVersion,
+			"language":       info.Language,
+		},
+	}
+
+	lines := make(map[string]*checker.LineIndex)
+	bases := make(map[string]string)
+	for _, item := range items {
+		base1 := run.baseID(item.Work1ID, item.Work1Name, item.Work1Dir, bases)
+		base2 := run.baseID(item.Work2ID, item.Work2Name, item.Work2Dir, bases)
+
+		for _, m := range item.Matches {
+			side1 := sarifSide{workTitle(item.Work1ID, item.Work1Name), base1, m.Work1File, m.Work1Start, m.Work1Size, m.Work1Cell}
+			side2 := sarifSide{workTitle(item.Work2ID, item.Work2Name), base2, m.Work2File, m.Work2Start, m.Work2Size, m.Work2Cell}
+
+			loc1 := side1.location(item.Work1Dir, item.OffsetUnit, lines)
+			loc2 := side2.location(item.Work2Dir, item.OffsetUnit, lines)
+
+			run.Results = append(run.Results,
+				sarifMatch(item, side1, loc1, side2, loc2),
+				sarifMatch(item, side2, loc2, side1, loc1))
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// baseID добавляет каталог работы в originalUriBaseIds и возвращает его идентификатор.
+// Bases: идентификаторы добавленных каталогов. Работам с одинаковыми названиями
+// из разных каталогов анализа (например, новой и старой) добавляется суффикс _2, _3, ...
+func (r *sarifRun) baseID(id uint64, name string, dir string, bases map[string]string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	if base, ok := bases[abs]; ok {
+		return base
+	}
+
+	prefix := "WORK_" + name
+	if id != 0 {
+		prefix = "WORK_" + strconv.FormatUint(id, 10)
+	}
+	base := prefix
+	for n := 2; ; n++ {
+		if _, ok := r.OriginalURIBaseIDs[base]; !ok {
+			break
+		}
+		base = prefix + "_" + strconv.Itoa(n)
+	}
+
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(abs) + "/"}
+	r.OriginalURIBaseIDs[base] = sarifArtifactLocation{URI: u.String()}
+	bases[abs] = base
+	return base
+}
+
+// sarifMatch формирует результат по фрагменту одной работы со ссылкой на фрагмент другой работы.
+func sarifMatch(item *checker.ReportItem, side sarifSide, loc sarifLocation, peer sarifSide, peerLoc sarifLocation) sarifResult {
+	peerLoc.ID = 1
+	peerLoc.Message = &sarifMessage{Text: fmt.Sprintf("%s: %s", peer.title, peer.file)}
+
+	properties := map[string]any{
+		"work1Id":    item.Work1ID,
+		"work2Id":    item.Work2ID,
+		"avg":        item.Avg,
+		"max":        item.Max,
+		"start":      side.start,
+		"size":       side.size,
+		"offsetUnit": item.OffsetUnit,
+	}
+	if side.cell != nil {
+		properties["cell"] = *side.cell
+	}
+
+	return sarifResult{
+		RuleID: sarifRuleID,
+		Level:  "warning",
+		Message: sarifMessage{Text: fmt.Sprintf("Фрагмент совпадает с [фрагментом](1) другой работы (%s, %s), схожесть работ %.1f%%",
+			peer.title, peer.file, item.Avg*100)},
+		Locations:        []sarifLocation{loc},
+		RelatedLocations: []sarifLocation{peerLoc},
+		PartialFingerprints: map[string]string{
+			"codeBorrowing/v1": fmt.Sprintf("%s:%s:%d:%d:%s:%s", side.base, side.file, side.start, side.size, peer.base, peer.file),
+		},
+		Properties: properties,
+	}
+}
+
+// location формирует место фрагмента в файле работы.
+// Область указывается строками и столбцами, если файл доступен и не является блокнотом Jupyter.
+func (s sarifSide) location(dir string, unit checker.OffsetUnit, lines map[string]*checker.LineIndex) sarifLocation {
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: (&url.URL{Path: s.file}).String(), URIBaseID: s.base},
+	}}
+	if s.cell != nil || preprocess.IsNotebook(s.file) {
+		return loc
+	}
+
+	filePath := path.Join(dir, s.file)
+	index, ok := lines[filePath]
+	if !ok {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return loc
+		}
+		index = checker.NewLineIndex(content)
+		lines[filePath] = index
+	}
+
+	from, to := index.FromUnit(s.start, unit), index.FromUnit(s.start+s.size, unit)
+	startLine, endLine := index.LineOf(from), index.LineOf(to)
+	loc.PhysicalLocation.Region = &sarifRegion{
+		StartLine:   startLine,
+		StartColumn: column(index, startLine, from),
+		EndLine:     endLine,
+		EndColumn:   column(index, endLine, to),
+	}
+	return loc
+}
+
+// column возвращает столбец (с 1) байтовой позиции в строке в единицах UTF-16.
+func column(index *checker.LineIndex, line int, offset int) int {
+	return int(index.ToUnit(offset, checker.UnitUTF16)-index.ToUnit(index.LineStart(line), checker.UnitUTF16)) + 1
+}
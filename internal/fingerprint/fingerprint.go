@@ -0,0 +1,205 @@
+package fingerprint
+
+import (
+	"bytes"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Количество токенов в k-грамме.
+	kgramSize = 8
+
+	// Размер окна алгоритма winnowing (в k-граммах).
+	windowSize = 4
+
+	// Максимальный размер индексируемого файла (1 Мб).
+	maxFileSize = 1024 * 1024
+)
+
+// Ключевые слова, которые не нормализуются в идентификатор.
+var keywords = map[string]struct{}{
+	"abstract": {}, "async": {}, "await": {}, "break": {}, "case": {}, "catch": {}, "class": {},
+	"const": {}, "continue": {}, "def": {}, "default": {}, "do": {}, "elif": {}, "else": {},
+	"enum": {}, "except": {}, "finally": {}, "for": {}, "foreach": {}, "from": {}, "if": {},
+	"import": {}, "in": {}, "interface": {}, "lambda": {}, "namespace": {}, "new": {}, "out": {},
+	"override": {}, "private": {}, "protected": {}, "public": {}, "ref": {}, "return": {},
+	"static": {}, "struct": {}, "switch": {}, "this": {}, "throw": {}, "try": {}, "using": {},
+	"var": {}, "virtual": {}, "void": {}, "while": {}, "with": {}, "yield": {},
+}
+
+// FromDirectory вычисляет отпечатки всех текстовых файлов каталога.
+func FromDirectory(root string) (map[uint64]struct{}, error) {
+	result := make(map[uint64]struct{})
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Каталоги и большие файлы пропускаем.
+		if info.IsDir() || info.Size() > maxFileSize {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		// Бинарные файлы пропускаем.
+		if bytes.IndexByte(content, 0) != -1 {
+			return nil
+		}
+
+		for _, fp := range Winnow(content) {
+			result[fp] = struct{}{}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// Winnow вычисляет отпечатки исходного кода алгоритмом winnowing.
+func Winnow(content []byte) []uint64 {
+	tokens := tokenize(content)
+	if len(tokens) < kgramSize {
+		return nil
+	}
+
+	// Хэши k-грамм.
+	hashes := make([]uint64, len(tokens)-kgramSize+1)
+	for i := range hashes {
+		h := fnv.New64a()
+		for _, token := range tokens[i : i+kgramSize] {
+			_, _ = h.Write([]byte(token))
+			_, _ = h.Write([]byte{0})
+		}
+		hashes[i] = h.Sum64()
+	}
+
+	// Если k-грамм меньше окна - берётся минимальный хэш.
+	if len(hashes) < windowSize {
+		return []uint64{minHash(hashes)}
+	}
+
+	// Выбор минимального хэша в каждом окне (крайнего правого при равенстве).
+	var result []uint64
+	last := -1
+	for i := 0; i+windowSize <= len(hashes); i++ {
+		idx := i
+		for j := i; j < i+windowSize; j++ {
+			if hashes[j] <= hashes[idx] {
+				idx = j
+			}
+		}
+
+		if idx != last {
+			result = append(result, hashes[idx])
+			last = idx
+		}
+	}
+
+	return result
+}
+
+// minHash возвращает минимальный хэш.
+func minHash(hashes []uint64) uint64 {
+	result := hashes[0]
+	for _, h := range hashes[1:] {
+		result = min(result, h)
+	}
+	return result
+}
+
+// tokenize разбивает исходный код на нормализованные токены.
+// Идентификаторы заменяются на "I", числа на "N", строки на "S",
+// пробелы и комментарии пропускаются.
+func tokenize(content []byte) []string {
+	var tokens []string
+
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRune(content[i:])
+
+		switch {
+		// Пробельные символы.
+		case unicode.IsSpace(r):
+			i += size
+
+		// Однострочный комментарий.
+		case bytes.HasPrefix(content[i:], []byte("//")):
+			i += skipLine(content[i:])
+
+		// Многострочный комментарий.
+		case bytes.HasPrefix(content[i:], []byte("/*")):
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end == -1 {
+				i = len(content)
+			} else {
+				i += end + 4
+			}
+
+		// Идентификатор или ключевое слово.
+		case r == '_' || unicode.IsLetter(r):
+			j := i + size
+			for j < len(content) {
+				r, size = utf8.DecodeRune(content[j:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				j += size
+			}
+
+			word := string(content[i:j])
+			if _, ok := keywords[word]; ok {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, "I")
+			}
+			i = j
+
+		// Число.
+		case unicode.IsDigit(r):
+			j := i + size
+			for j < len(content) && (content[j] == '.' || content[j] == '_' ||
+				unicode.IsLetter(rune(content[j])) || unicode.IsDigit(rune(content[j]))) {
+				j++
+			}
+			tokens = append(tokens, "N")
+			i = j
+
+		// Строка или символ.
+		case r == '"' || r == '\'':
+			j := i + 1
+			for j < len(content) && content[j] != byte(r) && content[j] != '\n' {
+				if content[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			tokens = append(tokens, "S")
+			i = min(j+1, len(content))
+
+		// Прочие символы.
+		default:
+			tokens = append(tokens, string(r))
+			i += size
+		}
+	}
+
+	return tokens
+}
+
+// skipLine возвращает длину строки вместе с символом переноса.
+func skipLine(content []byte) int {
+	idx := bytes.IndexByte(content, '\n')
+	if idx == -1 {
+		return len(content)
+	}
+	return idx + 1
+}
@@ -0,0 +1,112 @@
+package fingerprint
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Символы, каждый из которых становится отдельным токеном.
+const symbols = "+-*/%<>!&|^~?:;,.[]{}()="
+
+// randomCode генерирует n токенов из символов, разделённых пробелами.
+func randomCode(rnd *rand.Rand, n int) string {
+	sb := strings.Builder{}
+	for range n {
+		sb.WriteByte(symbols[rnd.Intn(len(symbols))])
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+func TestWinnowNormalization(t *testing.T) {
+	original := Winnow([]byte(`
+public static int sum(int[] values) {
+	int total = 0;
+	for (int i = 0; i < values.length; i++) {
+		total += values[i];
+	}
+	return total;
+}`))
+	if len(original) == 0 {
+		t.Fatal("отпечатки не вычислены")
+	}
+
+	// Переименование, другие литералы, пробелы и комментарии не меняют отпечатки.
+	renamed := Winnow([]byte(`/* копия */
+public static int add(int[] xs) { int s = 10; // сумма
+	for (int k = 1; k < xs.length; k++) { s += xs[k]; }
+	return s;
+}`))
+	if !slices.Equal(original, renamed) {
+		t.Errorf("отпечатки различаются:\n%v\n%v", original, renamed)
+	}
+
+	// Строки нормализуются в один токен.
+	if !slices.Equal(Winnow([]byte(`print("a b c"); x = 'q' + y + z + w;`)), Winnow([]byte(`print("d"); y = "x" + a + b + c;`))) {
+		t.Error("отпечатки строк различаются")
+	}
+}
+
+func TestWinnowShort(t *testing.T) {
+	// Токенов меньше размера k-граммы - отпечатков нет.
+	if result := Winnow([]byte("a = b;")); result != nil {
+		t.Errorf("Winnow = %v, ожидалось nil", result)
+	}
+
+	// K-грамм меньше окна - один минимальный отпечаток.
+	if result := Winnow([]byte("a = b + c * d;")); len(result) != 1 {
+		t.Errorf("Winnow = %v, ожидался один отпечаток", result)
+	}
+}
+
+func TestWinnowGuarantee(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	// Общий фрагмент не короче windowSize+kgramSize-1 токенов
+	// всегда даёт хотя бы один общий отпечаток.
+	for range 100 {
+		shared := randomCode(rnd, windowSize+kgramSize-1)
+		first := Winnow([]byte(randomCode(rnd, 50) + shared + randomCode(rnd, 50)))
+		second := Winnow([]byte(randomCode(rnd, 30) + shared + randomCode(rnd, 70)))
+
+		if !slices.ContainsFunc(first, func(h uint64) bool { return slices.Contains(second, h) }) {
+			t.Fatalf("нет общих отпечатков для фрагмента %q", shared)
+		}
+	}
+}
+
+func TestFromDirectory(t *testing.T) {
+	root := t.TempDir()
+	code := "public void run() { for (int i = 0; i < n; i++) { call(i); } }"
+
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "Main.java"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Бинарные файлы пропускаются.
+	if err := os.WriteFile(filepath.Join(root, "data.bin"), []byte("if (a) { b(); }\x00 c = d + e + f;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := FromDirectory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Winnow([]byte(code))
+	if len(result) != len(expected) {
+		t.Fatalf("FromDirectory вернул %d отпечатков, ожидалось %d", len(result), len(expected))
+	}
+	for _, fp := range expected {
+		if _, ok := result[fp]; !ok {
+			t.Errorf("отпечаток %d не найден", fp)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package fingerprint
+
+import (
+	"CodeBorrowing/internal/logger"
+	"CodeBorrowing/internal/utils"
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"strconv"
+	"strings"
+)
+
+// Заголовки sql сущностей.
+const (
+	sqlFingerprintsTable = "fingerprints"
+	sqlWorksTable        = "indexedWorks"
+	sqlAllowedTable      = "allowedWorks" // временная таблица работ, среди которых ищутся кандидаты.
+	sqlHash              = "hash"
+	sqlWorkId            = "workId"
+)
+
+// Sql запросы.
+var queryCreateFingerprintsTable = fmt.Sprintf("create table if not exists %s (%s integer, %s integer, primary key (%s, %s)) without rowid", sqlFingerprintsTable, sqlHash, sqlWorkId, sqlHash, sqlWorkId)
+var queryCreateFingerprintsIndex = fmt.Sprintf("create index if not exists %s_%s on %s (%s)", sqlFingerprintsTable, sqlWorkId, sqlFingerprintsTable, sqlWorkId)
+var queryCreateWorksTable = fmt.Sprintf("create table if not exists %s (%s integer primary key)", sqlWorksTable, sqlWorkId)
+var queryHasWork = fmt.Sprintf("select count(*) from %s where %s = $1", sqlWorksTable, sqlWorkId)
+var querySaveWork = fmt.Sprintf("insert or ignore into %s (%s) values ($1)", sqlWorksTable, sqlWorkId)
+var querySaveFingerprint = fmt.Sprintf("insert or ignore into %s (%s, %s) values ($1, $2)", sqlFingerprintsTable, sqlHash, sqlWorkId)
+var queryDeleteFingerprints = fmt.Sprintf("delete from %s where %s = $1", sqlFingerprintsTable, sqlWorkId)
+var queryDeleteWorksFormat = fmt.Sprintf("delete from %s where %s in (%%s)", sqlWorksTable, sqlWorkId)
+var queryDeleteWorksFingerprintsFormat = fmt.Sprintf("delete from %s where %s in (%%s)", sqlFingerprintsTable, sqlWorkId)
+var queryCreateAllowedTable = fmt.Sprintf("create temp table if not exists %s (%s integer primary key)", sqlAllowedTable, sqlWorkId)
+var queryClearAllowed = fmt.Sprintf("delete from %s", sqlAllowedTable)
+var querySaveAllowed = fmt.Sprintf("insert or ignore into %s (%s) values ($1)", sqlAllowedTable, sqlWorkId)
+var queryCandidates = fmt.Sprintf("select f.%s, count(*) as c from %s f join %s a on a.%s = f.%s where f.%s in (select %s from %s where %s = $1) and f.%s <> $1 group by f.%s order by c desc, f.%s limit $2",
+	sqlWorkId, sqlFingerprintsTable, sqlAllowedTable, sqlWorkId, sqlWorkId, sqlHash, sqlHash, sqlFingerprintsTable, sqlWorkId, sqlWorkId, sqlWorkId, sqlWorkId)
+
+// Index - инвертированный индекс отпечатков работ.
+type Index interface {
+	// Has проверяет, проиндексирована ли работа.
+	Has(workID uint64) bool
+
+	// Add индексирует работу, расположенную в каталоге path.
+	Add(workID uint64, path string) error
+
+	// Remove удаляет работы из индекса.
+	Remove(ids []uint64) error
+
+	// Candidates возвращает не более count работ из allowed,
+	// имеющих наибольшее число общих отпечатков с работой workID.
+	Candidates(workID uint64, allowed map[uint64]any, count int) ([]uint64, error)
+
+	// Close закрывает подключение к индексу.
+	Close() error
+}
+
+type index struct {
+	logger *logger.Logger
+	db     *sql.DB
+}
+
+// NewIndex создаёт индекс отпечатков в каталоге path.
+func NewIndex(logger *logger.Logger, path string) (Index, error) {
+	// Создание каталога для sqlite файла.
+	if _, err := utils.CreateDirectory(path); err != nil {
+		return nil, err
+	}
+
+	// Подключение к sqlite.
+	db, err := sql.Open("sqlite3", fmt.Sprintf("%s/index.db", path))
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверка подключения.
+	if err = db.Ping(); err != nil {
+		return nil, err
+	}
+
+	// Создание необходимых сущностей.
+	for _, query := range []string{queryCreateFingerprintsTable, queryCreateFingerprintsIndex, queryCreateWorksTable} {
+		if _, err = db.Exec(query); err != nil {
+			return nil, err
+		}
+	}
+
+	return &index{
+		logger: logger,
+		db:     db,
+	}, nil
+}
+
+// Close закрывает подключение к sqlite.
+func (i *index) Close() error {
+	return i.db.Close()
+}
+
+// Has проверяет, проиндексирована ли работа.
+func (i *index) Has(workID uint64) bool {
+	var count int
+	if err := i.db.QueryRow(queryHasWork, workID).Scan(&count); err != nil {
+		i.logger.Error(err)
+		return false
+	}
+
+	return count != 0
+}
+
+// Add индексирует работу, расположенную в каталоге path.
+// Предыдущие отпечатки работы заменяются.
+func (i *index) Add(workID uint64, path string) error {
+	fingerprints, err := FromDirectory(path)
+	if err != nil {
+		return err
+	}
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Удаление предыдущих отпечатков.
+	if _, err = tx.Exec(queryDeleteFingerprints, workID); err != nil {
+		return err
+	}
+
+	// Сохранение отпечатков.
+	stmt, err := tx.Prepare(querySaveFingerprint)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for fp := range fingerprints {
+		// Sqlite хранит знаковые целые.
+		if _, err = stmt.Exec(int64(fp), workID); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.Exec(querySaveWork, workID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Remove удаляет работы из индекса.
+func (i *index) Remove(ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	idsStr := join(ids)
+	if _, err := i.db.Exec(fmt.Sprintf(queryDeleteWorksFingerprintsFormat, idsStr)); err != nil {
+		return err
+	}
+	if _, err := i.db.Exec(fmt.Sprintf(queryDeleteWorksFormat, idsStr)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Candidates возвращает не более count работ из allowed,
+// имеющих наибольшее число общих отпечатков с работой workID.
+// При равном числе отпечатков работы упорядочиваются по идентификатору.
+func (i *index) Candidates(workID uint64, allowed map[uint64]any, count int) ([]uint64, error) {
+	if len(allowed) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	// Фильтрация и ограничение выполняются в sqlite, чтобы не читать все работы с общими отпечатками.
+	// Работы allowed передаются через временную таблицу: список в тексте запроса
+	// ограничен длиной запроса sqlite. Транзакция закрепляет подключение, в котором существует
+	// временная таблица, и отменяется после чтения, очищая таблицу.
+	tx, err := i.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{queryCreateAllowedTable, queryClearAllowed} {
+		if _, err = tx.Exec(query); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := tx.Prepare(querySaveAllowed)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	for id := range allowed {
+		if _, err = stmt.Exec(id); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.Query(queryCandidates, workID, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]uint64, 0, count)
+	for rows.Next() {
+		var id uint64
+		var shared int
+		if err = rows.Scan(&id, &shared); err != nil {
+			return nil, err
+		}
+		result = append(result, id)
+	}
+
+	return result, rows.Err()
+}
+
+// Join конвертирует []uint64 в строку с разделителем ','.
+func join(ids []uint64) string {
+	sb := strings.Builder{}
+	for i, id := range ids {
+		if i != 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(id, 10))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package fingerprint
+
+import (
+	"CodeBorrowing/internal/logger"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeWork создаёт каталог работы с одним файлом.
+func writeWork(t *testing.T, code string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.c"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestIndexCandidates(t *testing.T) {
+	index, err := NewIndex(logger.NewLogger(t.TempDir()), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer index.Close()
+
+	rnd := rand.New(rand.NewSource(2))
+	blocks := make([]string, 4)
+	for i := range blocks {
+		blocks[i] = randomCode(rnd, 200)
+	}
+
+	// Работа 1 проверяется, остальные имеют с ней убывающее число общих блоков.
+	works := map[uint64]string{
+		1: blocks[0] + blocks[1] + blocks[2] + blocks[3],
+		2: blocks[0] + blocks[1] + blocks[2] + randomCode(rnd, 200),
+		3: blocks[0] + blocks[1] + randomCode(rnd, 400),
+		4: blocks[0] + randomCode(rnd, 600),
+		5: randomCode(rnd, 800),
+		6: blocks[0] + blocks[1] + blocks[2] + blocks[3],
+	}
+	for id, code := range works {
+		if err = index.Add(id, writeWork(t, code)); err != nil {
+			t.Fatal(err)
+		}
+		if !index.Has(id) {
+			t.Fatalf("работа %d не проиндексирована", id)
+		}
+	}
+
+	// Большой список разрешённых работ (каждой новой работе разрешены все работы архива).
+	many := make([]uint64, 0, 200000)
+	for id := uint64(2); len(many) < cap(many); id++ {
+		many = append(many, id)
+	}
+
+	tests := []struct {
+		name     string
+		allowed  []uint64
+		count    int
+		expected []uint64
+	}{
+		{name: "много разрешённых", allowed: many, count: 10, expected: []uint64{6, 2, 3, 4}},
+		{name: "все работы", allowed: []uint64{2, 3, 4, 5}, count: 10, expected: []uint64{2, 3, 4}},
+		{name: "ограничение количества", allowed: []uint64{2, 3, 4, 5}, count: 2, expected: []uint64{2, 3}},
+		{name: "только разрешённые", allowed: []uint64{3, 5, 6}, count: 2, expected: []uint64{6, 3}},
+		{name: "проверяемая работа не возвращается", allowed: []uint64{1, 4}, count: 10, expected: []uint64{4}},
+		{name: "нет разрешённых", allowed: nil, count: 10, expected: nil},
+		{name: "нулевое количество", allowed: []uint64{2}, count: 0, expected: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			allowed := make(map[uint64]any)
+			for _, id := range test.allowed {
+				allowed[id] = nil
+			}
+
+			candidates, err := index.Candidates(1, allowed, test.count)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(candidates, test.expected) {
+				t.Errorf("Candidates = %v, ожидалось %v", candidates, test.expected)
+			}
+		})
+	}
+
+	// Удалённые работы не возвращаются.
+	if err = index.Remove([]uint64{2, 6}); err != nil {
+		t.Fatal(err)
+	}
+	if index.Has(2) {
+		t.Error("работа 2 осталась в индексе")
+	}
+
+	candidates, err := index.Candidates(1, map[uint64]any{2: nil, 3: nil, 6: nil}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(candidates, []uint64{3}) {
+		t.Errorf("Candidates после удаления = %v, ожидалось [3]", candidates)
+	}
+}
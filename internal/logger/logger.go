@@ -23,11 +23,17 @@ type Logger struct {
 	file                *os.File
 	updateLogFileTicker *time.Ticker
 	logDir              string
+	console             io.Writer
 }
 
 // NewLogger создаёт логгер приложения.
 func NewLogger(logDir string) *Logger {
-	inner, f, err := createLogrus(logDir)
+	return NewLoggerWithConsole(logDir, os.Stdout)
+}
+
+// NewLoggerWithConsole создаёт логгер приложения с выводом в указанный поток вместо stdout.
+func NewLoggerWithConsole(logDir string, console io.Writer) *Logger {
+	inner, f, err := createLogrus(logDir, console)
 	if err != nil {
 		panic(err)
 	}
@@ -35,6 +41,7 @@ func NewLogger(logDir string) *Logger {
 	instance := &Logger{
 		Logger:              inner,
 		logDir:              logDir,
+		console:             console,
 		file:                f,
 		updateLogFileTicker: time.NewTicker(updateLogTime),
 	}
@@ -54,7 +61,7 @@ func NewLogger(logDir string) *Logger {
 	return instance
 }
 
-func createLogrus(logDir string) (*logrus.Logger, *os.File, error) {
+func createLogrus(logDir string, console io.Writer) (*logrus.Logger, *os.File, error) {
 	instance := logrus.New()
 
 	instance.SetLevel(logrus.DebugLevel)  // логироват все уровни
@@ -79,7 +86,7 @@ func createLogrus(logDir string) (*logrus.Logger, *os.File, error) {
 
 	// Добавить вывод в консоль.
 	instance.AddHook(&writer.Hook{
-		Writer:    os.Stdout,
+		Writer:    console,
 		LogLevels: logrus.AllLevels,
 	})
 
@@ -9,7 +9,7 @@ import (
 // setOutputs устанавливает потоки вывода логов.
 func (log *Logger) updateLogFile() {
 	// Создание нового логгера.
-	newLogger, f, err := createLogrus(log.logDir)
+	newLogger, f, err := createLogrus(log.logDir, log.console)
 	if err != nil {
 		log.Error(err)
 		return
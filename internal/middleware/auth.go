@@ -19,3 +19,16 @@ func NewAuthInterceptor(token string) func(context.Context, string, any, any,
 		return invoker(newContext, method, req, reply, cc, opts...)
 	}
 }
+
+// NewAuthStreamInterceptor добавляет токен авторизации к потокам.
+func NewAuthStreamInterceptor(token string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		// Добавление токена авторизации.
+		md := metadata.New(map[string]string{"authorization": token})
+		newContext := metadata.NewOutgoingContext(ctx, md)
+
+		return streamer(newContext, desc, cc, method, opts...)
+	}
+}
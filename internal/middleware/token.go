@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Время действия токена, если сервер авторизации не сообщил его.
+const defaultTokenLifetime = 5 * time.Minute
+
+// Максимальный запас времени до истечения токена, при котором токен обновляется заранее.
+const maxRefreshMargin = 30 * time.Second
+
+// Tokens получает краткосрочные токены авторизации в обмен на долгосрочный ключ раннера
+// и обновляет их до истечения срока действия.
+//
+// Запрос: POST <url> с заголовком "Authorization: Bearer <ключ>".
+// Ответ: {"access_token": "...", "expires_in": <секунды>}. Если expires_in не указан,
+// срок действия берётся из поля exp токена JWT, иначе токен действует defaultTokenLifetime.
+type Tokens struct {
+	url    string
+	key    string
+	client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	refresh time.Time // время обновления токена (до истечения срока действия).
+}
+
+// tokenResponse - ответ сервера авторизации.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// NewTokens создаёт источник токенов сервера авторизации url для ключа key.
+func NewTokens(url string, key string, client *http.Client) *Tokens {
+	return &Tokens{url: url, key: key, client: client}
+}
+
+// Token возвращает действующий токен, при необходимости получая новый.
+func (t *Tokens) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Now().Before(t.refresh) {
+		return t.token, nil
+	}
+
+	token, lifetime, err := t.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	t.token = token
+	t.refresh = time.Now().Add(lifetime - min(lifetime/2, maxRefreshMargin))
+	return t.token, nil
+}
+
+// Invalidate сбрасывает токен, отклонённый сервером. Следующий вызов Token получит новый токен.
+func (t *Tokens) Invalidate(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Токен мог быть уже обновлён параллельным вызовом.
+	if t.token == token {
+		t.token = ""
+	}
+}
+
+// fetch получает новый токен и срок его действия.
+func (t *Tokens) fetch(ctx context.Context) (string, time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, http.NoBody)
+	if err != nil {
+		return "", 0, fmt.Errorf("авторизация: %v", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+t.key)
+
+	response, err := t.client.Do(request)
+	if err != nil {
+		return "", 0, fmt.Errorf("авторизация: %v", err)
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("авторизация: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("авторизация: сервер %s ответил %s: %s",
+			t.url, response.Status, strings.TrimSpace(string(content)))
+	}
+
+	var result tokenResponse
+	if err = json.Unmarshal(content, &result); err != nil {
+		return "", 0, fmt.Errorf("авторизация: некорректный ответ сервера %s: %v", t.url, err)
+	}
+	if result.AccessToken == "" {
+		return "", 0, fmt.Errorf("авторизация: сервер %s не вернул токен", t.url)
+	}
+
+	lifetime := time.Duration(result.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = jwtLifetime(result.AccessToken)
+	}
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+
+	return result.AccessToken, lifetime, nil
+}
+
+// jwtLifetime возвращает оставшееся время действия токена JWT по полю exp (0 - не удалось определить).
+func jwtLifetime(token string) time.Duration {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return 0
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return 0
+	}
+
+	return time.Until(time.Unix(claims.Exp, 0))
+}
+
+// NewTokenAuthInterceptor добавляет к вызовам краткосрочный токен авторизации.
+// Вызов, отклонённый с кодом Unauthenticated, повторяется один раз с новым токеном.
+func NewTokenAuthInterceptor(tokens *Tokens) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any,
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		token, err := tokens.Token(ctx)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		err = invoker(withToken(ctx, token), method, req, reply, cc, opts...)
+		if status.Code(err) != codes.Unauthenticated {
+			return err
+		}
+
+		// Токен отозван или истёк раньше срока: повтор с новым токеном.
+		tokens.Invalidate(token)
+		if token, err = tokens.Token(ctx); err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return invoker(withToken(ctx, token), method, req, reply, cc, opts...)
+	}
+}
+
+// withToken добавляет токен в метаданные authorization.
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// NewTokenAuthStreamInterceptor добавляет к потокам краткосрочный токен авторизации.
+// Поток не повторяется: если сервер отклонил токен, токен сбрасывается,
+// и при повторном открытии потока будет получен новый токен.
+func NewTokenAuthStreamInterceptor(tokens *Tokens) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		token, err := tokens.Token(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		stream, err := streamer(withToken(ctx, token), desc, cc, method, opts...)
+		if err != nil {
+			if status.Code(err) == codes.Unauthenticated {
+				tokens.Invalidate(token)
+			}
+			return nil, err
+		}
+
+		return &tokenStream{ClientStream: stream, tokens: tokens, token: token}, nil
+	}
+}
+
+// tokenStream - поток, сбрасывающий токен, отклонённый сервером.
+type tokenStream struct {
+	grpc.ClientStream
+	tokens *Tokens
+	token  string
+}
+
+func (s *tokenStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if status.Code(err) == codes.Unauthenticated {
+		s.tokens.Invalidate(s.token)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package orchestratortest
+
+import (
+	"encoding/json"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EnableTokens включает авторизацию краткосрочными токенами: токены выдаются по адресу AuthURL
+// в обмен на ключ (SetKey) и действуют lifetime, вызовы grpc с ключом вместо токена отклоняются.
+func (s *Server) EnableTokens(lifetime time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenLifetime = lifetime
+}
+
+// AuthURL возвращает адрес выдачи токенов.
+func (s *Server) AuthURL() string {
+	return s.files.URL + "/auth/token"
+}
+
+// RevokeTokens отзывает все выданные токены.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.tokens)
+}
+
+// IssuedTokens возвращает количество выданных токенов.
+func (s *Server) IssuedTokens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issued
+}
+
+// authorize проверяет значения метаданных authorization вызова grpc:
+// действующий токен, если токены включены, иначе ключ.
+func (s *Server) authorize(values []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tokenLifetime != 0 {
+		if len(values) != 0 {
+			token, _ := strings.CutPrefix(values[0], "Bearer ")
+			if expires, ok := s.tokens[token]; ok && time.Now().Before(expires) {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "недействительный токен авторизации")
+	}
+
+	if s.key != "" && (len(values) == 0 || values[0] != s.key) {
+		return status.Error(codes.Unauthenticated, "неверный ключ авторизации")
+	}
+	return nil
+}
+
+// serveToken выдаёт токен по запросу POST /auth/token с заголовком "Authorization: Bearer <ключ>".
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "ожидается POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	if s.tokenLifetime == 0 {
+		s.mu.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+s.key {
+		s.mu.Unlock()
+		http.Error(w, "неверный ключ авторизации", http.StatusUnauthorized)
+		return
+	}
+
+	s.issued++
+	token := fmt.Sprintf("token-%d", s.issued)
+	s.tokens[token] = time.Now().Add(s.tokenLifetime)
+	lifetime := s.tokenLifetime
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": token,
+		"expires_in":   int64(lifetime / time.Second),
+	})
+}
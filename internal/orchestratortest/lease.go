@@ -0,0 +1,87 @@
+package orchestratortest
+
+import (
+	"CodeBorrowing/services/orchestrator"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"slices"
+	"time"
+)
+
+// SetLease включает аренду задач: выданная задача, аренда которой не продлена за время lease,
+// снова становится новой и выдаётся повторно (0 - ExtendTaskLease не поддерживается).
+func (s *Server) SetLease(lease time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lease = lease
+}
+
+// LeaseCalls возвращает id задач вызовов ExtendTaskLease.
+func (s *Server) LeaseCalls() [][]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.leaseCalls)
+}
+
+// ReassignTask возвращает выданную задачу id в новые, как при истечении аренды:
+// при следующем продлении аренды задача возвращается раннеру в lostID.
+func (s *Server) ReassignTask(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		if t.ID == id && t.Status == StatusInProgress {
+			t.Status = StatusNew
+			delete(s.leases, t.ID)
+			s.notifySubscribers(t.EventID)
+		}
+	}
+}
+
+// take выдаёт задачу раннеру. Вызывается под s.mu.
+func (s *Server) take(t *orchestrator.Task) {
+	t.Status = StatusInProgress
+	if s.lease != 0 {
+		s.leases[t.ID] = time.Now().Add(s.lease)
+	}
+}
+
+// expireLeases возвращает в новые выданные задачи с истёкшей арендой. Вызывается под s.mu.
+func (s *Server) expireLeases() {
+	if s.lease == 0 {
+		return
+	}
+
+	for _, t := range s.tasks {
+		if expires, ok := s.leases[t.ID]; ok && t.Status == StatusInProgress && time.Now().After(expires) {
+			t.Status = StatusNew
+			delete(s.leases, t.ID)
+			s.notifySubscribers(t.EventID)
+		}
+	}
+}
+
+// ExtendTaskLease продлевает аренду выполняемых задач. Задачи, аренда которых истекла
+// или которые не выполняются, возвращаются в lostID.
+func (s *Server) ExtendTaskLease(_ context.Context, req *orchestrator.ExtendTaskLeaseRequest) (*orchestrator.ExtendTaskLeaseResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lease == 0 {
+		return nil, status.Error(codes.Unimplemented, "method ExtendTaskLease not implemented")
+	}
+	s.leaseCalls = append(s.leaseCalls, slices.Clone(req.GetID()))
+	s.expireLeases()
+
+	resp := &orchestrator.ExtendTaskLeaseResponse{LeaseSeconds: uint32(s.lease / time.Second)}
+	for _, id := range req.GetID() {
+		index := slices.IndexFunc(s.tasks, func(t *orchestrator.Task) bool { return t.ID == id })
+		if index == -1 || s.tasks[index].Status != StatusInProgress {
+			resp.LostID = append(resp.LostID, id)
+			continue
+		}
+		s.leases[id] = time.Now().Add(s.lease)
+	}
+	return resp, nil
+}
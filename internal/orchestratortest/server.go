@@ -0,0 +1,505 @@
+// Package orchestratortest - сервер Orchestrator в памяти для интеграционных тестов и локальной разработки.
+//
+// Сервер выдаёт настроенные задачи, работы событий и ссылки на скачивание работ
+// с локального файлового сервера HTTP, а также записывает все отчёты и закрытия задач.
+package orchestratortest
+
+import (
+	"CodeBorrowing/services/orchestrator"
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Состояния задач.
+const (
+	StatusNew        = "new"         // задача ожидает выдачи.
+	StatusInProgress = "in_progress" // задача выдана раннеру.
+	StatusDone       = "done"        // задача закрыта.
+	StatusError      = "error"       // задача закрыта с ошибкой.
+)
+
+// CloseCall - вызов CloseTask или CloseTaskWithError.
+type CloseCall struct {
+	ID        []uint64
+	WithError bool
+}
+
+// Server - сервер Orchestrator в памяти.
+type Server struct {
+	orchestrator.UnimplementedOrchestratorServer
+
+	mu             sync.Mutex
+	key            string
+	runner         *orchestrator.Runner
+	tasks          []*orchestrator.Task
+	events         map[uint64][]uint64
+	works          map[uint64][]byte
+	links          map[uint64]string
+	reports        []*orchestrator.SendCrossCheckReportRequest
+	defaultReports []*orchestrator.SendDefaultReportRequest
+	closeCalls     []CloseCall
+	calls          []string
+	trace          func(method string, req any)
+	tlsConfig      *tls.Config
+	tokenLifetime  time.Duration        // время действия токенов (0 - токены не выдаются).
+	tokens         map[string]time.Time // выданные токены и время их истечения.
+	issued         int
+	subscribers    map[*subscriber]struct{}
+	noSubscribe    bool                 // SubscribeTasks не поддерживается.
+	lease          time.Duration        // время аренды задач (0 - аренда не поддерживается).
+	leases         map[uint64]time.Time // время истечения аренды выданных задач.
+	leaseCalls     [][]uint64
+	capabilities   *orchestrator.RegisterRunnerCapabilitiesRequest
+
+	grpcServer *grpc.Server
+	listener   net.Listener
+	files      *httptest.Server
+}
+
+// NewServer создаёт сервер. Сервер запускается методом Start.
+func NewServer() *Server {
+	return &Server{
+		runner: &orchestrator.Runner{ID: 1, Name: "test"},
+		events: make(map[uint64][]uint64),
+		works:  make(map[uint64][]byte),
+		links:  make(map[uint64]string),
+		tokens: make(map[string]time.Time),
+
+		subscribers: make(map[*subscriber]struct{}),
+		leases:      make(map[uint64]time.Time),
+	}
+}
+
+// Start запускает сервер grpc на адресе addr (пустая строка - свободный порт на 127.0.0.1)
+// и файловый сервер HTTP для скачивания работ.
+func (s *Server) Start(addr string) error {
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.listener = listener
+	mux := http.NewServeMux()
+	mux.HandleFunc("/works/", s.serveWork)
+	mux.HandleFunc("/auth/token", s.serveToken)
+	s.files = httptest.NewServer(mux)
+	options := []grpc.ServerOption{grpc.UnaryInterceptor(s.intercept), grpc.StreamInterceptor(s.interceptStream)}
+	if s.tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+	s.grpcServer = grpc.NewServer(options...)
+	orchestrator.RegisterOrchestratorServer(s.grpcServer, s)
+
+	go func() {
+		_ = s.grpcServer.Serve(listener)
+	}()
+
+	return nil
+}
+
+// Addr возвращает адрес сервера grpc.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// FilesURL возвращает адрес файлового сервера HTTP.
+func (s *Server) FilesURL() string {
+	return s.files.URL
+}
+
+// Close останавливает сервер.
+func (s *Server) Close() {
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+	if s.files != nil {
+		s.files.Close()
+	}
+}
+
+// SetKey задаёт ключ, ожидаемый в метаданных authorization (пустая строка - ключ не проверяется).
+func (s *Server) SetKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+}
+
+// SetTLS задаёт параметры TLS сервера grpc (nil - без шифрования). Вызывается до Start.
+func (s *Server) SetTLS(tlsConfig *tls.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tlsConfig = tlsConfig
+}
+
+// SetTrace задаёт функцию, вызываемую при каждом вызове метода сервера (nil - не вызывается).
+func (s *Server) SetTrace(trace func(method string, req any)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trace = trace
+}
+
+// SetRunner задаёт сведения о раннере для GetRunnerInfo.
+func (s *Server) SetRunner(runner *orchestrator.Runner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runner = runner
+}
+
+// AddWork добавляет работу события. Files: содержимое файлов работы по пути внутри архива.
+func (s *Server) AddWork(eventID uint64, workID uint64, files map[string]string) error {
+	buf := bytes.Buffer{}
+	writer := zip.NewWriter(&buf)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		w, err := writer.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write([]byte(files[name])); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	s.AddWorkArchive(eventID, workID, buf.Bytes())
+	return nil
+}
+
+// AddWorkDir добавляет работу события из файлов каталога dir.
+func (s *Server) AddWorkDir(eventID uint64, workID uint64, dir string) error {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.AddWork(eventID, workID, files)
+}
+
+// AddWorkArchive добавляет работу события в виде zip-архива.
+func (s *Server) AddWorkArchive(eventID uint64, workID uint64, archive []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.Contains(s.events[eventID], workID) {
+		s.events[eventID] = append(s.events[eventID], workID)
+	}
+	s.works[workID] = archive
+}
+
+// SetDownloadLink задаёт ссылку на скачивание работы вместо файлового сервера.
+func (s *Server) SetDownloadLink(workID uint64, link string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links[workID] = link
+}
+
+// AddTask добавляет новую задачу.
+func (s *Server) AddTask(id uint64, eventID uint64, workID uint64, tag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks = append(s.tasks, &orchestrator.Task{
+		ID:      id,
+		EventID: eventID,
+		WorkID:  workID,
+		Tag:     tag,
+		Status:  StatusNew,
+	})
+	s.notifySubscribers(eventID)
+}
+
+// TaskStatus возвращает состояние задачи (пустая строка - задача не найдена).
+func (s *Server) TaskStatus(id uint64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		if t.ID == id {
+			return t.Status
+		}
+	}
+	return ""
+}
+
+// Reports возвращает полученные отчёты SendCrossCheckReport.
+func (s *Server) Reports() []*orchestrator.SendCrossCheckReportRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.reports)
+}
+
+// DefaultReports возвращает полученные отчёты SendDefaultReport.
+func (s *Server) DefaultReports() []*orchestrator.SendDefaultReportRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.defaultReports)
+}
+
+// Capabilities возвращает последние зарегистрированные возможности раннера (nil - не регистрировались).
+func (s *Server) Capabilities() *orchestrator.RegisterRunnerCapabilitiesRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capabilities
+}
+
+// CloseCalls возвращает вызовы CloseTask и CloseTaskWithError.
+func (s *Server) CloseCalls() []CloseCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.closeCalls)
+}
+
+// Calls возвращает названия вызванных методов в порядке вызова.
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls)
+}
+
+// intercept записывает вызовы и проверяет авторизацию.
+func (s *Server) intercept(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+
+	s.mu.Lock()
+	s.calls = append(s.calls, method)
+	trace := s.trace
+	s.mu.Unlock()
+
+	if trace != nil {
+		trace(method, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if err := s.authorize(md.Get("authorization")); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// interceptStream записывает открытие потоков и проверяет авторизацию.
+func (s *Server) interceptStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+
+	s.mu.Lock()
+	s.calls = append(s.calls, method)
+	trace := s.trace
+	s.mu.Unlock()
+
+	if trace != nil {
+		trace(method, nil)
+	}
+
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if err := s.authorize(md.Get("authorization")); err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
+
+// serveWork отдаёт архив работы по пути /works/<id>.zip.
+func (s *Server) serveWork(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.URL.Path, "/works/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := strconv.ParseUint(strings.TrimSuffix(name, ".zip"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	archive, ok := s.works[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+	_, _ = w.Write(archive)
+}
+
+// GetRunnerInfo возвращает сведения о раннере.
+func (s *Server) GetRunnerInfo(context.Context, *emptypb.Empty) (*orchestrator.GetRunnerInfoResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &orchestrator.GetRunnerInfoResponse{Runner: proto.Clone(s.runner).(*orchestrator.Runner)}, nil
+}
+
+// RegisterRunnerCapabilities записывает возможности раннера.
+func (s *Server) RegisterRunnerCapabilities(_ context.Context, req *orchestrator.RegisterRunnerCapabilitiesRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capabilities = proto.Clone(req).(*orchestrator.RegisterRunnerCapabilitiesRequest)
+	return &emptypb.Empty{}, nil
+}
+
+// GetNewTask выдаёт первую новую задачу.
+func (s *Server) GetNewTask(context.Context, *emptypb.Empty) (*orchestrator.GetNewTaskResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLeases()
+
+	for _, t := range s.tasks {
+		if t.Status == StatusNew {
+			s.take(t)
+			return &orchestrator.GetNewTaskResponse{Task: proto.Clone(t).(*orchestrator.Task)}, nil
+		}
+	}
+	return &orchestrator.GetNewTaskResponse{}, nil
+}
+
+// GetAllNewTasksOfEvent выдаёт все новые задачи события.
+// Если событие не указано, выбирается событие первой новой задачи.
+func (s *Server) GetAllNewTasksOfEvent(_ context.Context, req *orchestrator.GetAllNewTasksOfEventRequest) (*orchestrator.GetAllNewTasksOfEventResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLeases()
+
+	eventID := req.GetEventID()
+	if eventID == 0 {
+		for _, t := range s.tasks {
+			if t.Status == StatusNew {
+				eventID = t.EventID
+				break
+			}
+		}
+	}
+
+	resp := &orchestrator.GetAllNewTasksOfEventResponse{}
+	for _, t := range s.tasks {
+		if t.Status == StatusNew && t.EventID == eventID {
+			s.take(t)
+			resp.Task = append(resp.Task, proto.Clone(t).(*orchestrator.Task))
+		}
+	}
+	return resp, nil
+}
+
+// CloseTask закрывает задачи.
+func (s *Server) CloseTask(_ context.Context, req *orchestrator.CloseTaskRequest) (*emptypb.Empty, error) {
+	return s.closeTasks(req.GetID(), false)
+}
+
+// CloseTaskWithError закрывает задачи с ошибкой.
+func (s *Server) CloseTaskWithError(_ context.Context, req *orchestrator.CloseTaskRequest) (*emptypb.Empty, error) {
+	return s.closeTasks(req.GetID(), true)
+}
+
+// closeTasks записывает закрытие задач и обновляет их состояние.
+func (s *Server) closeTasks(ids []uint64, withError bool) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeCalls = append(s.closeCalls, CloseCall{ID: slices.Clone(ids), WithError: withError})
+
+	for _, t := range s.tasks {
+		if !slices.Contains(ids, t.ID) {
+			continue
+		}
+		if withError {
+			t.Status = StatusError
+		} else {
+			t.Status = StatusDone
+		}
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetWorksOfEvent возвращает id работ события.
+func (s *Server) GetWorksOfEvent(_ context.Context, req *orchestrator.GetWorksOfEventRequest) (*orchestrator.GetWorksOfEventResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	works, ok := s.events[req.GetEventID()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("событие %d не найдено", req.GetEventID()))
+	}
+	return &orchestrator.GetWorksOfEventResponse{WorkID: slices.Clone(works)}, nil
+}
+
+// GetWorksDownloadLinks возвращает ссылки на скачивание работ с файлового сервера.
+func (s *Server) GetWorksDownloadLinks(_ context.Context, req *orchestrator.GetWorksDownloadLinksRequest) (*orchestrator.GetWorksDownloadLinksResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &orchestrator.GetWorksDownloadLinksResponse{}
+	for _, id := range req.GetWorkID() {
+		link, ok := s.links[id]
+		if !ok {
+			link = fmt.Sprintf("%s/works/%d.zip", s.files.URL, id)
+		}
+		resp.Item = append(resp.Item, &orchestrator.GetWorksDownloadLinksResponseItem{WorkID: id, DownloadLink: link})
+	}
+	return resp, nil
+}
+
+// SendCrossCheckReport записывает отчёт о паре работ.
+func (s *Server) SendCrossCheckReport(_ context.Context, req *orchestrator.SendCrossCheckReportRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports = append(s.reports, proto.Clone(req).(*orchestrator.SendCrossCheckReportRequest))
+	return &emptypb.Empty{}, nil
+}
+
+// SendDefaultReport записывает отчёт о работе.
+func (s *Server) SendDefaultReport(_ context.Context, req *orchestrator.SendDefaultReportRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultReports = append(s.defaultReports, proto.Clone(req).(*orchestrator.SendDefaultReportRequest))
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,87 @@
+package orchestratortest
+
+import (
+	"CodeBorrowing/services/orchestrator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// subscriber - подписчик на уведомления о новых задачах.
+type subscriber struct {
+	events chan uint64   // события новых задач.
+	drop   chan struct{} // закрывается при разрыве потока сервером.
+}
+
+// DisableSubscribe отключает поток уведомлений: SubscribeTasks возвращает Unimplemented,
+// как сервер без поддержки уведомлений.
+func (s *Server) DisableSubscribe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.noSubscribe = true
+}
+
+// DropSubscribers разрывает потоки уведомлений всех подписчиков с кодом Unavailable.
+func (s *Server) DropSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers {
+		close(sub.drop)
+	}
+	clear(s.subscribers)
+}
+
+// Subscribers возвращает количество подписчиков на уведомления.
+func (s *Server) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+// notifySubscribers отправляет подписчикам уведомление о новой задаче события. Вызывается под s.mu.
+func (s *Server) notifySubscribers(eventID uint64) {
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- eventID:
+		default: // подписчик не успевает: уведомления объединяются.
+		}
+	}
+}
+
+// SubscribeTasks отправляет уведомление сразу после подписки (eventID = 0 - проверить задачи)
+// и при каждой новой задаче.
+func (s *Server) SubscribeTasks(_ *emptypb.Empty, stream grpc.ServerStreamingServer[orchestrator.TaskNotification]) error {
+	s.mu.Lock()
+	if s.noSubscribe {
+		s.mu.Unlock()
+		return status.Error(codes.Unimplemented, "method SubscribeTasks not implemented")
+	}
+	sub := &subscriber{events: make(chan uint64, 16), drop: make(chan struct{})}
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+	}()
+
+	if err := stream.Send(&orchestrator.TaskNotification{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-sub.drop:
+			return status.Error(codes.Unavailable, "поток уведомлений разорван сервером")
+		case eventID := <-sub.events:
+			if err := stream.Send(&orchestrator.TaskNotification{EventID: eventID}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package preprocess
+
+import (
+	"bufio"
+	"encoding/xml"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Проект в файле решения: Project("{тип}") = "название", "путь", "{id}".
+var slnProjectRe = regexp.MustCompile(`^Project\("[^"]*"\)\s*=\s*"[^"]*"\s*,\s*"([^"]+)"`)
+
+// Каталоги сборки проекта, которые не анализируются.
+var csharpOutputDirs = []string{"bin", "obj"}
+
+// Каталог пакетов NuGet (packages.config).
+const nugetPackagesDir = "packages"
+
+// csprojDTO - файл проекта C# (SDK и классический формат).
+type csprojDTO struct {
+	Sdk  string `xml:"Sdk,attr"`
+	Sdks []struct {
+		Name string `xml:"Name,attr"`
+	} `xml:"Sdk"`
+	PropertyGroups []struct {
+		EnableDefaultItems        string `xml:"EnableDefaultItems"`
+		EnableDefaultCompileItems string `xml:"EnableDefaultCompileItems"`
+	} `xml:"PropertyGroup"`
+	ItemGroups []struct {
+		Compile []struct {
+			Include string `xml:"Include,attr"`
+			Remove  string `xml:"Remove,attr"`
+		} `xml:"Compile"`
+	} `xml:"ItemGroup"`
+}
+
+// csharpProjects - компилируемые файлы проектов C# работы.
+type csharpProjects struct {
+	sources map[string]struct{} // исходные файлы, входящие в сборку.
+	outputs []string            // каталоги сборки проектов и пакетов NuGet.
+}
+
+// readCSharpProjects читает проекты C# работы: проекты из файлов решений (.sln),
+// а если решений нет - все файлы проектов (.csproj). Возвращает nil, если проектов нет.
+func readCSharpProjects(dir string) (*csharpProjects, error) {
+	// Все файлы работы.
+	var files, solutions, projects []string
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		files = append(files, rel)
+		switch strings.ToLower(path.Ext(rel)) {
+		case ".sln":
+			solutions = append(solutions, rel)
+		case ".csproj":
+			projects = append(projects, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Проекты решений.
+	if len(solutions) != 0 {
+		projects = projects[:0]
+		for _, sln := range solutions {
+			slnProjects, err := readSolution(dir, sln)
+			if err != nil {
+				return nil, err
+			}
+			projects = append(projects, slnProjects...)
+		}
+	}
+	if len(projects) == 0 {
+		return nil, nil
+	}
+
+	result := &csharpProjects{sources: make(map[string]struct{})}
+	for _, project := range projects {
+		if err = result.readProject(dir, project, files); err != nil {
+			return nil, err
+		}
+	}
+
+	// Если проекты не удалось разобрать, файлы работы не ограничиваются.
+	if len(result.sources) == 0 {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+// readSolution читает пути к проектам C# файла решения относительно каталога работы.
+func readSolution(dir string, sln string) ([]string, error) {
+	f, err := os.Open(path.Join(dir, sln))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		match := slnProjectRe.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil || !strings.EqualFold(path.Ext(match[1]), ".csproj") {
+			continue
+		}
+
+		project, ok := resolvePath(path.Dir(sln), match[1])
+		if !ok {
+			continue
+		}
+		if _, err = os.Stat(path.Join(dir, project)); err == nil {
+			result = append(result, project)
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// readProject добавляет компилируемые файлы проекта.
+// Files: все файлы работы.
+func (c *csharpProjects) readProject(dir string, project string, files []string) error {
+	content, err := os.ReadFile(path.Join(dir, project))
+	if err != nil {
+		return err
+	}
+
+	var dto csprojDTO
+	if err = xml.Unmarshal(content, &dto); err != nil {
+		// Некорректный проект не ограничивает файлы работы.
+		return nil
+	}
+
+	projectDir := path.Dir(project)
+	for _, name := range csharpOutputDirs {
+		c.outputs = append(c.outputs, path.Join(projectDir, name))
+	}
+	c.outputs = append(c.outputs, path.Join(path.Dir(projectDir), nugetPackagesDir), path.Join(projectDir, nugetPackagesDir))
+
+	// Проект SDK по умолчанию компилирует все файлы .cs каталога проекта, кроме bin и obj.
+	sdk := dto.Sdk != "" || len(dto.Sdks) != 0
+	defaultItems := sdk
+	for _, group := range dto.PropertyGroups {
+		if strings.EqualFold(strings.TrimSpace(group.EnableDefaultItems), "false") ||
+			strings.EqualFold(strings.TrimSpace(group.EnableDefaultCompileItems), "false") {
+			defaultItems = false
+		}
+	}
+
+	sources := make(map[string]struct{})
+	if defaultItems {
+		for _, file := range files {
+			if isCSharpSource(file) && c.inProject(projectDir, file) {
+				sources[file] = struct{}{}
+			}
+		}
+	}
+
+	// Явно указанные и исключённые файлы (регистр в путях Windows не учитывается).
+	for _, group := range dto.ItemGroups {
+		for _, item := range group.Compile {
+			for _, pattern := range projectPatterns(projectDir, item.Include) {
+				for _, file := range files {
+					if matchSegments(pattern, strings.Split(strings.ToLower(file), "/")) {
+						sources[file] = struct{}{}
+					}
+				}
+			}
+			for _, pattern := range projectPatterns(projectDir, item.Remove) {
+				for file := range sources {
+					if matchSegments(pattern, strings.Split(strings.ToLower(file), "/")) {
+						delete(sources, file)
+					}
+				}
+			}
+		}
+	}
+
+	for file := range sources {
+		c.sources[file] = struct{}{}
+	}
+	return nil
+}
+
+// inProject проверяет, что файл находится в каталоге проекта вне каталогов сборки и скрытых каталогов.
+func (c *csharpProjects) inProject(projectDir string, file string) bool {
+	rel := file
+	if projectDir != "." {
+		var ok bool
+		if rel, ok = strings.CutPrefix(file, projectDir+"/"); !ok {
+			return false
+		}
+	}
+
+	segments := strings.Split(rel, "/")
+	for _, segment := range segments[:len(segments)-1] {
+		if strings.HasPrefix(segment, ".") {
+			return false
+		}
+		for _, name := range csharpOutputDirs {
+			if strings.EqualFold(segment, name) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// excludeDir проверяет, что каталог является каталогом сборки или пакетов NuGet.
+func (c *csharpProjects) excludeDir(rel string) bool {
+	for _, output := range c.outputs {
+		if strings.EqualFold(rel, output) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeFile проверяет, что исходный файл C# не входит в сборку проектов.
+func (c *csharpProjects) excludeFile(rel string) bool {
+	if !isCSharpSource(rel) {
+		return false
+	}
+	_, ok := c.sources[rel]
+	return !ok
+}
+
+// isCSharpSource проверяет, что файл является исходным файлом C#.
+func isCSharpSource(rel string) bool {
+	return strings.EqualFold(path.Ext(rel), ".cs")
+}
+
+// projectPatterns переводит значение атрибута Include/Remove в шаблоны путей относительно каталога работы
+// в нижнем регистре. Значения со свойствами MSBuild ($(...)) не вычисляются и пропускаются.
+func projectPatterns(projectDir string, value string) [][]string {
+	var result [][]string
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.Contains(item, "$(") || strings.Contains(item, "@(") {
+			continue
+		}
+
+		resolved, ok := resolvePath(projectDir, item)
+		if !ok {
+			continue
+		}
+		result = append(result, strings.Split(strings.ToLower(resolved), "/"))
+	}
+	return result
+}
+
+// resolvePath переводит путь Windows относительно каталога base в путь относительно каталога работы.
+// Ok = false, если путь выходит за пределы работы или является абсолютным.
+func resolvePath(base string, value string) (string, bool) {
+	value = strings.ReplaceAll(value, `\`, "/")
+	if path.IsAbs(value) || strings.Contains(value, ":") {
+		return "", false
+	}
+
+	resolved := path.Join(base, value)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return "", false
+	}
+	return resolved, true
+}
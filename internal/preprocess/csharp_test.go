@@ -0,0 +1,194 @@
+package preprocess
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Проект SDK без дополнительных настроек.
+const sdkProject = `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><OutputType>Exe</OutputType></PropertyGroup></Project>`
+
+func TestReadCSharpProjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		sources  []string // файлы .cs, входящие в сборку.
+		excluded []string // файлы .cs, не входящие в сборку.
+		dirs     []string // исключаемые каталоги.
+	}{
+		{
+			name: "SDK: файлы по умолчанию",
+			files: map[string]string{
+				"App.csproj":         sdkProject,
+				"Program.cs":         "",
+				"Models/User.cs":     "",
+				"bin/Debug/Gen.cs":   "",
+				"obj/Debug/Attr.cs":  "",
+				"OBJ/Release/Old.cs": "",
+				".vs/Temp.cs":        "",
+				"README.md":          "",
+			},
+			sources:  []string{"Models/User.cs", "Program.cs"},
+			excluded: []string{"bin/Debug/Gen.cs", "obj/Debug/Attr.cs", "OBJ/Release/Old.cs", ".vs/Temp.cs"},
+			dirs:     []string{"bin", "obj", "packages"},
+		},
+		{
+			name: "SDK: Include и Remove с путями Windows",
+			files: map[string]string{
+				"App/App.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><EnableDefaultCompileItems>false</EnableDefaultCompileItems></PropertyGroup>
+  <ItemGroup>
+    <Compile Include="Src\**\*.cs" />
+    <Compile Remove="Src\Old\*.cs" />
+    <Compile Include="..\Shared\Common.cs;C:\Libs\Lib.cs;$(Generated)\G.cs" />
+  </ItemGroup>
+</Project>`,
+				"App/Program.cs":       "",
+				"App/Src/A.cs":         "",
+				"App/Src/Deep/B.cs":    "",
+				"App/Src/Old/C.cs":     "",
+				"Shared/Common.cs":     "",
+				"Shared/Unlisted.cs":   "",
+				"App/bin/Debug/Gen.cs": "",
+			},
+			sources:  []string{"App/Src/A.cs", "App/Src/Deep/B.cs", "Shared/Common.cs"},
+			excluded: []string{"App/Program.cs", "App/Src/Old/C.cs", "Shared/Unlisted.cs"},
+			dirs:     []string{"App/bin", "App/obj", "App/packages", "packages"},
+		},
+		{
+			name: "классический проект: только Compile Include",
+			files: map[string]string{
+				"Lab.csproj": `<?xml version="1.0" encoding="utf-8"?>
+<Project ToolsVersion="15.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <ItemGroup>
+    <Compile Include="PROGRAM.cs" />
+    <Compile Include="Properties\AssemblyInfo.cs" />
+  </ItemGroup>
+</Project>`,
+				"Program.cs":                 "",
+				"Properties/AssemblyInfo.cs": "",
+				"Draft.cs":                   "",
+			},
+			sources:  []string{"Program.cs", "Properties/AssemblyInfo.cs"},
+			excluded: []string{"Draft.cs"},
+		},
+		{
+			name: "решение: только проекты решения внутри работы",
+			files: map[string]string{
+				"Lab.sln": `Microsoft Visual Studio Solution File, Format Version 12.00
+Project("{9A19103F-16F7-4668-BE54-9A1E7A4F7556}") = "App", "App\App.csproj", "{11111111-1111-1111-1111-111111111111}"
+EndProject
+Project("{9A19103F-16F7-4668-BE54-9A1E7A4F7556}") = "Outside", "..\Outside\Outside.csproj", "{22222222-2222-2222-2222-222222222222}"
+EndProject
+Project("{9A19103F-16F7-4668-BE54-9A1E7A4F7556}") = "Missing", "Missing\Missing.csproj", "{33333333-3333-3333-3333-333333333333}"
+EndProject
+Project("{2150E333-8FDC-42A3-9474-1A3956D46DE8}") = "Docs", "Docs", "{44444444-4444-4444-4444-444444444444}"
+EndProject`,
+				"App/App.csproj":     sdkProject,
+				"App/Program.cs":     "",
+				"Extra/Extra.csproj": sdkProject,
+				"Extra/Extra.cs":     "",
+			},
+			sources:  []string{"App/Program.cs"},
+			excluded: []string{"Extra/Extra.cs"},
+			dirs:     []string{"App/bin", "App/obj"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, test.files)
+
+			projects, err := readCSharpProjects(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if projects == nil {
+				t.Fatal("проекты не найдены")
+			}
+
+			var sources []string
+			for file := range projects.sources {
+				sources = append(sources, file)
+			}
+			slices.Sort(sources)
+			if !slices.Equal(sources, test.sources) {
+				t.Errorf("файлы сборки = %v, ожидалось %v", sources, test.sources)
+			}
+
+			for _, file := range test.sources {
+				if projects.excludeFile(file) {
+					t.Errorf("файл %s исключён", file)
+				}
+			}
+			for _, file := range test.excluded {
+				if !projects.excludeFile(file) {
+					t.Errorf("файл %s не исключён", file)
+				}
+			}
+			for _, d := range test.dirs {
+				if !projects.excludeDir(d) {
+					t.Errorf("каталог %s не исключён", d)
+				}
+			}
+
+			// Файлы не C# не ограничиваются.
+			if projects.excludeFile("README.md") {
+				t.Error("файл README.md исключён")
+			}
+		})
+	}
+}
+
+func TestReadCSharpProjectsNone(t *testing.T) {
+	tests := map[string]map[string]string{
+		"нет проектов":            {"Program.cs": ""},
+		"некорректный проект":     {"App.csproj": "<Project", "Program.cs": ""},
+		"проект без файлов .cs":   {"App.csproj": sdkProject, "README.md": ""},
+		"решение без проектов C#": {"Lab.sln": "", "App/App.csproj": sdkProject, "App/Program.cs": ""},
+	}
+
+	// Если проекты не удалось прочитать, файлы работы не ограничиваются.
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, files)
+
+			projects, err := readCSharpProjects(dir)
+			if err != nil || projects != nil {
+				t.Errorf("readCSharpProjects = %+v, %v, ожидалось nil", projects, err)
+			}
+		})
+	}
+}
+
+func TestPrepareCSharp(t *testing.T) {
+	root := path.Join(t.TempDir(), "new")
+	writeFiles(t, root, map[string]string{
+		"101/App.csproj":       sdkProject,
+		"101/Program.cs":       "class Program { }",
+		"101/bin/Debug/Gen.cs": "class Gen { }",
+		"101/packages/x.dll":   "",
+		"101/Notes.txt":        "notes",
+		"102/Main.cs":          "class Main { }",
+		"102/bin/Main.cs":      "class Main { }",
+	})
+
+	prepared, err := newTestPipeline(t, Options{CSharp: true}).Prepare([]string{root}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prepared.Remove()
+
+	// Работа без проектов не ограничивается.
+	expected := []string{"101/App.csproj", "101/Notes.txt", "101/Program.cs", "102/Main.cs", "102/bin/Main.cs"}
+	if files := listFiles(t, prepared.New[0]); !slices.Equal(files, expected) {
+		t.Errorf("файлы = %v, ожидалось %v", files, expected)
+	}
+	if !strings.HasSuffix(prepared.New[0], "new") {
+		t.Errorf("каталог подготовленных работ = %s", prepared.New[0])
+	}
+}
@@ -0,0 +1,131 @@
+package preprocess
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Ignore - правила игнорирования файлов в формате .gitignore.
+type Ignore struct {
+	rules []ignoreRule
+}
+
+// ignoreRule - правило игнорирования.
+type ignoreRule struct {
+	text    string   // исходная строка правила.
+	pattern []string // части шаблона.
+	negate  bool     // правило "!": файл не игнорируется.
+	dirOnly bool     // правило "/" в конце: только каталоги.
+}
+
+// ParseIgnore читает правила игнорирования в формате .gitignore.
+// Шаблон без "/" (кроме завершающего) сравнивается с файлом или каталогом на любом уровне,
+// шаблон с "/" - с путём от корня работы. Последнее подходящее правило определяет результат.
+func ParseIgnore(content string) *Ignore {
+	ignore := &Ignore{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{text: line}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// Шаблон без "/" относится к любому уровню.
+		if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		rule.pattern = strings.Split(strings.TrimPrefix(line, "/"), "/")
+
+		ignore.rules = append(ignore.rules, rule)
+	}
+
+	return ignore
+}
+
+// LoadIgnore читает правила игнорирования события из каталога dir:
+// default.gitignore, tag/<тег>.gitignore для каждого тега и event/<id события>.gitignore.
+// Правила объединяются в этом порядке. Отсутствующие файлы пропускаются.
+func LoadIgnore(dir string, eventID uint64, tags []string) (*Ignore, error) {
+	files := []string{path.Join(dir, "default.gitignore")}
+	for _, tag := range tags {
+		if tag != "" && !strings.ContainsAny(tag, `/\`) && tag != "." && tag != ".." {
+			files = append(files, path.Join(dir, "tag", tag+".gitignore"))
+		}
+	}
+	files = append(files, path.Join(dir, "event", strconv.FormatUint(eventID, 10)+".gitignore"))
+
+	sb := strings.Builder{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		sb.Write(content)
+		sb.WriteString("\n")
+	}
+
+	return ParseIgnore(sb.String()), nil
+}
+
+// Empty проверяет, что правил нет.
+func (i *Ignore) Empty() bool {
+	return i == nil || len(i.rules) == 0
+}
+
+// Match проверяет, что файл или каталог игнорируется.
+// Rel: путь относительно каталога работы. Возвращает результат и последнее подходящее правило.
+func (i *Ignore) Match(rel string, isDir bool) (bool, string) {
+	if i == nil {
+		return false, ""
+	}
+
+	segments := strings.Split(rel, "/")
+	ignored, text := false, ""
+	for _, rule := range i.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(rule.pattern, segments) {
+			ignored, text = !rule.negate, rule.text
+		}
+	}
+
+	return ignored, text
+}
+
+// String возвращает правила в формате .gitignore, по правилу в строке.
+func (i *Ignore) String() string {
+	if i == nil {
+		return ""
+	}
+
+	sb := strings.Builder{}
+	for _, rule := range i.rules {
+		sb.WriteString(rule.text)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
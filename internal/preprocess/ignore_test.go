@@ -0,0 +1,123 @@
+package preprocess
+
+import (
+	"path"
+	"testing"
+)
+
+func TestIgnoreMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		rel     string
+		isDir   bool
+		ignored bool
+	}{
+		{name: "название на любом уровне", rules: "*.log", rel: "src/logs/app.log", ignored: true},
+		{name: "название не совпадает", rules: "*.log", rel: "src/app.cs", ignored: false},
+		{name: "каталог на любом уровне", rules: "node_modules", rel: "web/node_modules", isDir: true, ignored: true},
+		{name: "шаблон с / от корня", rules: "/build", rel: "build", isDir: true, ignored: true},
+		{name: "шаблон с / не на корне", rules: "/build", rel: "src/build", isDir: true, ignored: false},
+		{name: "шаблон с / в середине от корня", rules: "doc/*.txt", rel: "doc/notes.txt", ignored: true},
+		{name: "шаблон с / в середине не на корне", rules: "doc/*.txt", rel: "src/doc/notes.txt", ignored: false},
+		{name: "шаблон с / в середине без вложенных", rules: "doc/*.txt", rel: "doc/a/notes.txt", ignored: false},
+		{name: "/ в конце - каталог", rules: "out/", rel: "src/out", isDir: true, ignored: true},
+		{name: "/ в конце - не файл", rules: "out/", rel: "src/out", isDir: false, ignored: false},
+		{name: "** в начале", rules: "**/generated/*.cs", rel: "a/b/generated/X.cs", ignored: true},
+		{name: "** в середине", rules: "src/**/Test*.cs", rel: "src/a/b/TestMain.cs", ignored: true},
+		{name: "** в середине без каталогов", rules: "src/**/Test*.cs", rel: "src/TestMain.cs", ignored: true},
+		{name: "** в конце", rules: "vendor/**", rel: "vendor/lib/x.go", ignored: true},
+		{name: "отрицание", rules: "*.cs\n!Main.cs", rel: "src/Main.cs", ignored: false},
+		{name: "отрицание не затрагивает другие файлы", rules: "*.cs\n!Main.cs", rel: "src/Util.cs", ignored: true},
+		{name: "последнее правило определяет результат", rules: "!Main.cs\n*.cs", rel: "Main.cs", ignored: true},
+		{name: "экранирование !", rules: `\!important.txt`, rel: "!important.txt", ignored: true},
+		{name: "комментарии и пустые строки", rules: "# *.cs\n\n   \n", rel: "Main.cs", ignored: false},
+		{name: "пробелы в конце строки и CRLF", rules: "*.tmp  \r\n", rel: "a.tmp", ignored: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ignored, rule := ParseIgnore(test.rules).Match(test.rel, test.isDir)
+			if ignored != test.ignored {
+				t.Errorf("Match(%q) = %v (правило %q), ожидалось %v", test.rel, ignored, rule, test.ignored)
+			}
+		})
+	}
+
+	// Без правил ничего не игнорируется.
+	var ignore *Ignore
+	if ignored, _ := ignore.Match("a.cs", false); ignored || !ignore.Empty() {
+		t.Error("nil правила игнорируют файл")
+	}
+}
+
+func TestLoadIgnore(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"default.gitignore":       "*.txt\n*.log\n",
+		"tag/python.gitignore":    "!keep.txt\n__pycache__/\n",
+		"tag/java.gitignore":      "*.class\n",
+		"event/7.gitignore":       "keep.txt\n!debug.log\n",
+		"event/8.gitignore":       "*.py\n",
+		"tag/../escape.gitignore": "*.cs\n",
+	})
+
+	tests := []struct {
+		name    string
+		eventID uint64
+		tags    []string
+		rel     string
+		isDir   bool
+		ignored bool
+	}{
+		{name: "правила по умолчанию", eventID: 1, rel: "notes.txt", ignored: true},
+		{name: "тег отменяет правило по умолчанию", eventID: 1, tags: []string{"python"}, rel: "keep.txt", ignored: false},
+		{name: "правило тега", eventID: 1, tags: []string{"python"}, rel: "src/__pycache__", isDir: true, ignored: true},
+		{name: "правило другого тега не действует", eventID: 1, tags: []string{"python"}, rel: "Main.class", ignored: false},
+		{name: "правила нескольких тегов", eventID: 1, tags: []string{"python", "java"}, rel: "Main.class", ignored: true},
+		{name: "событие отменяет правило тега", eventID: 7, tags: []string{"python"}, rel: "keep.txt", ignored: true},
+		{name: "событие отменяет правило по умолчанию", eventID: 7, rel: "debug.log", ignored: false},
+		{name: "правило другого события не действует", eventID: 7, rel: "main.py", ignored: false},
+		{name: "тег с путём пропускается", eventID: 1, tags: []string{"../escape"}, rel: "Main.cs", ignored: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ignore, err := LoadIgnore(dir, test.eventID, test.tags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ignored, rule := ignore.Match(test.rel, test.isDir); ignored != test.ignored {
+				t.Errorf("Match(%q) = %v (правило %q), ожидалось %v", test.rel, ignored, rule, test.ignored)
+			}
+		})
+	}
+
+	// Без файлов правил правил нет.
+	ignore, err := LoadIgnore(path.Join(dir, "missing"), 1, nil)
+	if err != nil || !ignore.Empty() {
+		t.Errorf("LoadIgnore без файлов = %v, %v", ignore, err)
+	}
+}
+
+func TestPrepareIgnore(t *testing.T) {
+	root := path.Join(t.TempDir(), "new")
+	writeFiles(t, root, map[string]string{
+		"101/Main.cs":          "class Main { }",
+		"101/Test.cs":          "class Test { }",
+		"101/build/Out.cs":     "class Out { }",
+		"101/build/keep/In.cs": "class In { }",
+	})
+
+	// Файлы игнорируемого каталога не возвращаются отрицанием, как и в git.
+	ignore := ParseIgnore("Test.cs\nbuild/\n!build/keep/In.cs\n")
+	prepared, err := newTestPipeline(t, Options{}).Prepare([]string{root}, nil, ignore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prepared.Remove()
+
+	if files := listFiles(t, prepared.New[0]); len(files) != 1 || files[0] != "101/Main.cs" {
+		t.Errorf("файлы = %v, ожидалось [101/Main.cs]", files)
+	}
+}
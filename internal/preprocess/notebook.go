@@ -0,0 +1,145 @@
+package preprocess
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Расширение файлов блокнотов Jupyter.
+const notebookExt = ".ipynb"
+
+// Расширение исходного файла блокнота по умолчанию.
+const defaultNotebookExt = ".py"
+
+// notebookDTO - содержимое блокнота Jupyter (nbformat 4).
+type notebookDTO struct {
+	Cells    []notebookCellDTO `json:"cells"`
+	Metadata struct {
+		LanguageInfo struct {
+			FileExtension string `json:"file_extension"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+type notebookCellDTO struct {
+	CellType string          `json:"cell_type"`
+	Source   json.RawMessage `json:"source"` // строка или список строк.
+}
+
+// cell - ячейка блокнота в исходном файле, извлечённом из блокнота.
+type cell struct {
+	index uint32 // номер ячейки в блокноте (с 0, включая ячейки без кода).
+	start int    // байтовая позиция начала ячейки в извлечённом файле.
+	end   int    // байтовая позиция конца ячейки в извлечённом файле.
+}
+
+// NotebookCell - ячейка с кодом блокнота Jupyter.
+type NotebookCell struct {
+	Index  uint32 // Номер ячейки в блокноте (с 0, включая ячейки без кода).
+	Source string // Текст ячейки.
+}
+
+// ReadNotebook читает ячейки с кодом блокнота Jupyter.
+func ReadNotebook(content []byte) ([]NotebookCell, error) {
+	var notebook notebookDTO
+	if err := json.Unmarshal(content, &notebook); err != nil {
+		return nil, fmt.Errorf("некорректный блокнот: %v", err)
+	}
+
+	var result []NotebookCell
+	for i, c := range notebook.Cells {
+		if c.CellType != "code" {
+			continue
+		}
+
+		source, err := readCellSource(c.Source)
+		if err != nil {
+			return nil, fmt.Errorf("ячейка %d: %v", i, err)
+		}
+		result = append(result, NotebookCell{Index: uint32(i), Source: source})
+	}
+
+	return result, nil
+}
+
+// IsNotebook проверяет, что файл является блокнотом Jupyter.
+func IsNotebook(rel string) bool {
+	return strings.HasSuffix(strings.ToLower(rel), notebookExt)
+}
+
+// extractNotebook извлекает ячейки с кодом блокнота в исходный файл.
+// Ячейки разделяются пустой строкой. Возвращает содержимое, расширение исходного файла и ячейки.
+func extractNotebook(content []byte) ([]byte, string, []cell, error) {
+	var notebook notebookDTO
+	if err := json.Unmarshal(content, &notebook); err != nil {
+		return nil, "", nil, fmt.Errorf("некорректный блокнот: %v", err)
+	}
+
+	ext := notebook.Metadata.LanguageInfo.FileExtension
+	if ext == "" || strings.ContainsAny(ext, `/\`) {
+		ext = defaultNotebookExt
+	}
+
+	notebookCells, err := ReadNotebook(content)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var result []byte
+	var cells []cell
+	for _, c := range notebookCells {
+		if len(result) != 0 {
+			result = append(result, '\n', '\n')
+		}
+		cells = append(cells, cell{index: c.Index, start: len(result), end: len(result) + len(c.Source)})
+		result = append(result, c.Source...)
+	}
+
+	return append(result, '\n'), ext, cells, nil
+}
+
+// readCellSource читает текст ячейки (строка или список строк).
+func readCellSource(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var source string
+	if json.Unmarshal(raw, &source) == nil {
+		return source, nil
+	}
+
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// cellFragment - часть фрагмента извлечённого файла внутри ячейки.
+type cellFragment struct {
+	cell     cell
+	from, to int // байтовые позиции части в извлечённом файле.
+}
+
+// splitCells разбивает фрагмент [from, to) извлечённого файла по ячейкам, с которыми он пересекается.
+// Разделители ячеек в части не входят. Фрагмент нулевой длины относится к ячейке, которой он касается.
+func splitCells(cells []cell, from int, to int) []cellFragment {
+	var result []cellFragment
+	for _, c := range cells {
+		if f, t := max(from, c.start), min(to, c.end); f < t {
+			result = append(result, cellFragment{cell: c, from: f, to: t})
+		}
+	}
+	if len(result) != 0 {
+		return result
+	}
+
+	for _, c := range cells {
+		if f, t := max(from, c.start), min(to, c.end); f <= t {
+			return []cellFragment{{cell: c, from: f, to: f}}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package preprocess
+
+import (
+	"CodeBorrowing/internal/checker"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+// Блокнот: ячейки 0 и 2 с кодом (строка и список строк), ячейка 1 - текст.
+const testNotebook = `{
+  "cells": [
+    {"cell_type": "code", "source": "import os\nx = 1"},
+    {"cell_type": "markdown", "source": ["# Заголовок"]},
+    {"cell_type": "code", "source": ["def f():\n", "    return 'я😀'"]}
+  ],
+  "metadata": {"language_info": {"file_extension": ".r"}}
+}`
+
+func TestExtractNotebook(t *testing.T) {
+	content, ext, cells, err := extractNotebook([]byte(testNotebook))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ячейки с кодом разделяются пустой строкой, расширение - из метаданных.
+	expected := "import os\nx = 1\n\ndef f():\n    return 'я😀'\n"
+	if string(content) != expected || ext != ".r" {
+		t.Errorf("extractNotebook = %q, %q, ожидалось %q, .r", content, ext, expected)
+	}
+
+	// Номера ячеек с учётом ячеек без кода, позиции в извлечённом файле.
+	if len(cells) != 2 || cells[0] != (cell{index: 0, start: 0, end: 15}) ||
+		cells[1] != (cell{index: 2, start: 17, end: len(expected) - 1}) {
+		t.Errorf("ячейки = %+v", cells)
+	}
+	for _, c := range cells {
+		if text := string(content[c.start:c.end]); strings.HasPrefix(text, "\n") || strings.HasSuffix(text, "\n\n") {
+			t.Errorf("ячейка %d: %q", c.index, text)
+		}
+	}
+}
+
+func TestExtractNotebookExt(t *testing.T) {
+	tests := []struct {
+		metadata string
+		ext      string
+	}{
+		{metadata: `{}`, ext: ".py"},
+		{metadata: `{"language_info": {"file_extension": ""}}`, ext: ".py"},
+		{metadata: `{"language_info": {"file_extension": "/../x"}}`, ext: ".py"},
+		{metadata: `{"language_info": {"file_extension": ".jl"}}`, ext: ".jl"},
+	}
+
+	for _, test := range tests {
+		_, ext, _, err := extractNotebook([]byte(`{"cells": [], "metadata": ` + test.metadata + `}`))
+		if err != nil || ext != test.ext {
+			t.Errorf("метаданные %s: расширение %q (%v), ожидалось %q", test.metadata, ext, err, test.ext)
+		}
+	}
+
+	if _, _, _, err := extractNotebook([]byte("not json")); err == nil {
+		t.Error("extractNotebook: ожидалась ошибка для некорректного блокнота")
+	}
+	if !IsNotebook("a/Lab.IPYNB") || IsNotebook("lab.ipynb.py") {
+		t.Error("IsNotebook: неверное определение блокнота")
+	}
+}
+
+func TestRestoreNotebook(t *testing.T) {
+	root := path.Join(t.TempDir(), "new")
+	other := path.Join(t.TempDir(), "old", "102")
+	writeFiles(t, root, map[string]string{"101/lab.ipynb": testNotebook})
+	writeFiles(t, other, map[string]string{"lab.py": "x = 1\ndef f():\n"})
+
+	prepared, err := newTestPipeline(t, Options{Notebooks: true}).Prepare([]string{root}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prepared.Remove()
+
+	workDir := path.Join(prepared.New[0], "101")
+	content, err := os.ReadFile(path.Join(workDir, "lab.ipynb.r"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := checker.NewLineIndex(content)
+	fragment := func(text string) (uint64, uint64) {
+		offset := strings.Index(string(content), text)
+		start := lines.ToUnit(offset, checker.UnitUTF16)
+		return start, lines.ToUnit(offset+len(text), checker.UnitUTF16) - start
+	}
+
+	// Фрагмент в одной ячейке и фрагмент, захватывающий обе ячейки.
+	start1, size1 := fragment("'я😀'")
+	start2, size2 := fragment("x = 1\n\ndef f():\n")
+	item := &checker.ReportItem{
+		Work1Dir:   workDir,
+		Work2Dir:   other,
+		OffsetUnit: checker.UnitUTF16,
+		Matches: []checker.MatchItem{
+			{Work1File: "lab.ipynb.r", Work1Start: start1, Work1Size: size1, Work2File: "lab.py", Work2Start: 0, Work2Size: 1},
+			{Work1File: "lab.ipynb.r", Work1Start: start2, Work1Size: size2, Work2File: "lab.py", Work2Start: 0, Work2Size: 15},
+		},
+	}
+	if err = prepared.Restore([]*checker.ReportItem{item}); err != nil {
+		t.Fatal(err)
+	}
+
+	type part struct {
+		file        string
+		cell        uint32
+		start, size uint64
+	}
+	expected := []part{
+		{file: "lab.ipynb", cell: 2, start: 20, size: 5},
+		{file: "lab.ipynb", cell: 0, start: 10, size: 5},
+		{file: "lab.ipynb", cell: 2, start: 0, size: 9},
+	}
+	if len(item.Matches) != len(expected) {
+		t.Fatalf("совпадений %d, ожидалось %d: %+v", len(item.Matches), len(expected), item.Matches)
+	}
+	for i, m := range item.Matches {
+		if m.Work1Cell == nil {
+			t.Fatalf("совпадение %d: ячейка не указана", i)
+		}
+		if got := (part{m.Work1File, *m.Work1Cell, m.Work1Start, m.Work1Size}); got != expected[i] {
+			t.Errorf("совпадение %d: %+v, ожидалось %+v", i, got, expected[i])
+		}
+	}
+
+	// Фрагмент другой работы сохраняется для каждой части.
+	for _, m := range item.Matches[1:] {
+		if m.Work2File != "lab.py" || m.Work2Start != 0 || m.Work2Size != 15 || m.Work2Cell != nil {
+			t.Errorf("фрагмент другой работы изменён: %+v", m)
+		}
+	}
+	if item.Work1Dir != path.Join(root, "101") {
+		t.Errorf("каталог работы = %s", item.Work1Dir)
+	}
+}
@@ -0,0 +1,244 @@
+package preprocess
+
+import (
+	"CodeBorrowing/internal/logger"
+	"CodeBorrowing/internal/utils"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Options - параметры подготовки исходного кода.
+type Options struct {
+	Exclude       []string `json:"exclude,omitempty"` // Шаблоны исключаемых файлов.
+	SkipGenerated bool     `json:"skip_generated"`    // Исключать автоматически сгенерированные файлы и каталоги bin/obj.
+	SkipBinary    bool     `json:"skip_binary"`       // Исключать бинарные файлы.
+	MaxFileSize   int64    `json:"max_file_size"`     // Максимальный размер файла в байтах (0 - без ограничения).
+	NormalizeEOL  bool     `json:"normalize_eol"`     // Заменять переводы строк "\r\n" на "\n".
+	Notebooks     bool     `json:"notebooks"`         // Извлекать ячейки с кодом из блокнотов Jupyter.
+	CSharp        bool     `json:"csharp"`            // Анализировать только файлы C#, входящие в сборку проектов (.sln/.csproj).
+}
+
+// Enabled проверяет, что подготовка включена хотя бы одним параметром.
+func (o Options) Enabled() bool {
+	return len(o.Exclude) != 0 || o.SkipGenerated || o.SkipBinary || o.MaxFileSize != 0 || o.NormalizeEOL ||
+		o.Notebooks || o.CSharp
+}
+
+// Pipeline - подготовка исходного кода работ перед анализом.
+// Работы копируются в отдельный каталог с исключением и преобразованием файлов,
+// исходные работы в хранилище и архиве не изменяются.
+type Pipeline struct {
+	logger  *logger.Logger
+	dir     string
+	options Options
+}
+
+// NewPipeline создаёт подготовку исходного кода.
+// Dir: каталог для подготовленных работ (очищается при каждой подготовке).
+func NewPipeline(logger *logger.Logger, dir string, options Options) *Pipeline {
+	return &Pipeline{
+		logger:  logger,
+		dir:     dir,
+		options: options,
+	}
+}
+
+// Prepare подготавливает каталоги с работами для анализа.
+// Каждый каталог из newWorks и oldWorks, как и при анализе, содержит работы в подкаталогах.
+// Ignore: правила игнорирования файлов события (nil - без правил).
+func (p *Pipeline) Prepare(newWorks []string, oldWorks []string, ignore *Ignore) (*Prepared, error) {
+	if err := utils.ClearDirectory(p.dir); err != nil {
+		return nil, err
+	}
+
+	prepared := &Prepared{
+		dir:   p.dir,
+		New:   make([]string, 0, len(newWorks)),
+		Old:   make([]string, 0, len(oldWorks)),
+		works: make(map[string]*work),
+	}
+
+	// Название каталога сохраняется: по нему анализатор называет работы.
+	for i, root := range append(append([]string{}, newWorks...), oldWorks...) {
+		dst := path.Join(p.dir, strconv.Itoa(i), path.Base(root))
+		if err := p.prepareRoot(prepared, root, dst, ignore); err != nil {
+			return nil, fmt.Errorf("подготовка работ %s: %v", root, err)
+		}
+
+		if i < len(newWorks) {
+			prepared.New = append(prepared.New, dst)
+		} else {
+			prepared.Old = append(prepared.Old, dst)
+		}
+	}
+
+	return prepared, nil
+}
+
+// prepareRoot подготавливает работы каталога root в каталоге dst.
+func (p *Pipeline) prepareRoot(prepared *Prepared, root string, dst string, ignore *Ignore) error {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(dst, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			p.logger.Debugf("Подготовка: файл %s вне каталога работы пропущен", path.Join(root, entry.Name()))
+			continue
+		}
+
+		w := &work{
+			dir:   path.Join(root, entry.Name()),
+			files: make(map[string]fileMap),
+		}
+		if err = p.prepareWork(w, path.Join(dst, entry.Name()), ignore); err != nil {
+			return err
+		}
+		prepared.works[path.Join(dst, entry.Name())] = w
+	}
+
+	return nil
+}
+
+// prepareWork копирует файлы работы в каталог dst с исключением и преобразованием.
+func (p *Pipeline) prepareWork(w *work, dst string, ignore *Ignore) error {
+	var excluded int
+	var ignored []string // файлы и каталоги, исключённые правилами игнорирования.
+
+	// Проекты C# работы.
+	var projects *csharpProjects
+	if p.options.CSharp {
+		var err error
+		if projects, err = readCSharpProjects(w.dir); err != nil {
+			return err
+		}
+	}
+
+	err := filepath.Walk(w.dir, func(srcPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(w.dir, srcPath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		// Правила игнорирования события.
+		if rel != "." {
+			if ok, _ := ignore.Match(rel, info.IsDir()); ok {
+				ignored = append(ignored, rel)
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		if info.IsDir() {
+			// Исключённые каталоги не обходятся.
+			if rel != "." && (p.excludeDir(rel) || projects != nil && projects.excludeDir(rel)) {
+				p.logger.Debugf("Подготовка: каталог %s/%s исключён", w.dir, rel)
+				excluded++
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), os.ModePerm)
+		}
+
+		reason := p.excludeFile(rel, info)
+		if reason == "" && projects != nil && projects.excludeFile(rel) {
+			reason = "не входит в сборку проектов C#"
+		}
+		if reason != "" {
+			p.logger.Debugf("Подготовка: файл %s/%s исключён (%s)", w.dir, rel, reason)
+			excluded++
+			return nil
+		}
+
+		content, err := os.ReadFile(srcPath)
+		if err != nil {
+			return err
+		}
+
+		if p.options.SkipBinary && isBinary(content) {
+			p.logger.Debugf("Подготовка: файл %s/%s исключён (бинарный)", w.dir, rel)
+			excluded++
+			return nil
+		}
+
+		// Блокнот Jupyter заменяется исходным файлом с ячейками кода.
+		if p.options.Notebooks && IsNotebook(rel) {
+			extracted, ext, cells, err := extractNotebook(content)
+			if err != nil {
+				p.logger.Warnf("Подготовка: блокнот %s/%s пропущен: %v", w.dir, rel, err)
+				excluded++
+				return nil
+			}
+			w.files[rel+ext] = fileMap{source: rel, cells: cells}
+			return os.WriteFile(filepath.Join(dst, rel+ext), extracted, info.Mode().Perm())
+		}
+
+		// Преобразование содержимого.
+		m := fileMap{source: rel}
+		if p.options.NormalizeEOL {
+			content, m.removed = normalizeEOL(content)
+		}
+		w.files[rel] = m
+
+		return os.WriteFile(filepath.Join(dst, rel), content, info.Mode().Perm())
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(ignored) != 0 {
+		p.logger.Infof("Подготовка работы %s: игнорируются %s", w.dir, strings.Join(ignored, ", "))
+	}
+	if excluded != 0 {
+		p.logger.Infof("Подготовка работы %s: исключено файлов и каталогов: %d", w.dir, excluded)
+	}
+
+	return nil
+}
+
+// excludeDir проверяет, что каталог исключается из анализа.
+func (p *Pipeline) excludeDir(rel string) bool {
+	if p.options.SkipGenerated && isGenerated(rel) {
+		return true
+	}
+	for _, pattern := range p.options.Exclude {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeFile проверяет, что файл исключается из анализа. Возвращает причину исключения.
+func (p *Pipeline) excludeFile(rel string, info os.FileInfo) string {
+	if !info.Mode().IsRegular() {
+		return "не является обычным файлом"
+	}
+	if p.options.SkipGenerated && isGenerated(rel) {
+		return "сгенерирован автоматически"
+	}
+	for _, pattern := range p.options.Exclude {
+		if matchGlob(pattern, rel) {
+			return "шаблон " + pattern
+		}
+	}
+	if p.options.MaxFileSize != 0 && info.Size() > p.options.MaxFileSize {
+		return fmt.Sprintf("размер %d байт", info.Size())
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package preprocess
+
+import (
+	"CodeBorrowing/internal/checker"
+	"CodeBorrowing/internal/logger"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFiles создаёт файлы files (путь - содержимое) в каталоге root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		filePath := path.Join(root, name)
+		if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestPipeline создаёт подготовку исходного кода во временном каталоге.
+func newTestPipeline(t *testing.T, options Options) *Pipeline {
+	return NewPipeline(logger.NewLogger(t.TempDir()), path.Join(t.TempDir(), "prepared"), options)
+}
+
+func TestPrepareRestore(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		fragment string // текст фрагмента в подготовленном файле.
+		unit     checker.OffsetUnit
+		start    uint64 // ожидаемая позиция в исходном файле.
+		size     uint64
+	}{
+		{name: "CRLF", content: "a\r\nbc\r\nd", fragment: "bc\nd", unit: checker.UnitRunes, start: 3, size: 5},
+		{name: "начало файла", content: "ab\r\ncd", fragment: "ab", unit: checker.UnitRunes, start: 0, size: 2},
+		{name: "конец файла", content: "x\r\ny\r\n", fragment: "y\n", unit: checker.UnitRunes, start: 3, size: 3},
+		{name: "табуляция", content: "\tif\r\n\t\treturn", fragment: "\t\treturn", unit: checker.UnitRunes, start: 5, size: 8},
+		{name: "символы вне BMP в UTF-16", content: "я😀\r\nб\r\n", fragment: "б", unit: checker.UnitUTF16, start: 5, size: 1},
+		{name: "многобайтовые символы в байтах", content: "я😀\r\nб\r\n", fragment: "б\n", unit: checker.UnitBytes, start: 8, size: 4},
+		{name: "многобайтовые символы в символах", content: "я😀\r\nб\r\n", fragment: "😀\nб", unit: checker.UnitRunes, start: 1, size: 4},
+		{name: "одиночный CR", content: "a\rb\r\nc", fragment: "b\nc", unit: checker.UnitRunes, start: 2, size: 4},
+		{name: "без CRLF", content: "a\nb\nc", fragment: "b\nc", unit: checker.UnitRunes, start: 2, size: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := path.Join(t.TempDir(), "new")
+			other := path.Join(t.TempDir(), "old", "102")
+			writeFiles(t, root, map[string]string{"101/src/Main.cs": test.content})
+			writeFiles(t, other, map[string]string{"Main.cs": test.content})
+
+			prepared, err := newTestPipeline(t, Options{NormalizeEOL: true}).Prepare([]string{root}, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer prepared.Remove()
+
+			// Позиция фрагмента в подготовленном файле.
+			workDir := path.Join(prepared.New[0], "101")
+			content, err := os.ReadFile(path.Join(workDir, "src", "Main.cs"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(content), "\r") {
+				t.Fatalf("подготовленный файл содержит \\r: %q", content)
+			}
+			offset := strings.Index(string(content), test.fragment)
+			if offset == -1 {
+				t.Fatalf("фрагмент %q не найден в %q", test.fragment, content)
+			}
+			lines := checker.NewLineIndex(content)
+			start := lines.ToUnit(offset, test.unit)
+			size := lines.ToUnit(offset+len(test.fragment), test.unit) - start
+
+			item := &checker.ReportItem{
+				Work1Dir:   workDir,
+				Work2Dir:   other,
+				OffsetUnit: test.unit,
+				Matches: []checker.MatchItem{{
+					Work1File: "src/Main.cs", Work1Start: start, Work1Size: size,
+					Work2File: "Main.cs", Work2Start: start, Work2Size: size,
+				}},
+			}
+			if err = prepared.Restore([]*checker.ReportItem{item}); err != nil {
+				t.Fatal(err)
+			}
+
+			// Подготовленная работа заменяется исходной, позиции - позициями исходного файла.
+			m := item.Matches[0]
+			if item.Work1Dir != path.Join(root, "101") || m.Work1File != "src/Main.cs" {
+				t.Errorf("работа = %s, файл = %s", item.Work1Dir, m.Work1File)
+			}
+			if m.Work1Start != test.start || m.Work1Size != test.size {
+				t.Errorf("позиция = %d, %d, ожидалось %d, %d", m.Work1Start, m.Work1Size, test.start, test.size)
+			}
+
+			// Работа, которая не подготавливалась, не изменяется.
+			if item.Work2Dir != other || m.Work2Start != start || m.Work2Size != size {
+				t.Errorf("неподготовленная работа изменена: %s, %d, %d", item.Work2Dir, m.Work2Start, m.Work2Size)
+			}
+		})
+	}
+}
+
+func TestPrepareExclude(t *testing.T) {
+	root := path.Join(t.TempDir(), "new")
+	writeFiles(t, root, map[string]string{
+		"101/Main.cs":                "class Main { }",
+		"101/Main.Designer.cs":       "partial class Main { }",
+		"101/bin/Debug/App.cs":       "class App { }",
+		"101/lib/data.bin":           "\x00\x01",
+		"101/big.cs":                 strings.Repeat("x", 200),
+		"101/tests/MainTests.cs":     "class MainTests { }",
+		"101/Properties/Settings.cs": "class Settings { }",
+		"Notes.cs":                   "class Notes { }",
+	})
+
+	pipeline := newTestPipeline(t, Options{
+		Exclude:       []string{"tests"},
+		SkipGenerated: true,
+		SkipBinary:    true,
+		MaxFileSize:   100,
+	})
+	prepared, err := pipeline.Prepare([]string{root}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prepared.Remove()
+
+	var files []string
+	for _, dir := range prepared.New {
+		files = append(files, listFiles(t, dir)...)
+	}
+
+	// Файлы вне каталогов работ, как и при проверке количества работ, не переносятся.
+	expected := []string{"101/Main.cs", "101/Properties/Settings.cs"}
+	if !slices.Equal(files, expected) {
+		t.Errorf("файлы = %v, ожидалось %v", files, expected)
+	}
+}
+
+// listFiles возвращает отсортированные пути файлов каталога root относительно него.
+func listFiles(t *testing.T, root string) []string {
+	t.Helper()
+
+	var result []string
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, filePath)
+		result = append(result, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(result)
+	return result
+}
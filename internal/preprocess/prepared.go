@@ -0,0 +1,162 @@
+package preprocess
+
+import (
+	"CodeBorrowing/internal/checker"
+	"os"
+	"path"
+	"sort"
+)
+
+// Prepared - подготовленные для анализа работы.
+type Prepared struct {
+	New []string // Каталоги с подготовленными новыми работами.
+	Old []string // Каталоги с подготовленными старыми работами.
+
+	dir   string
+	works map[string]*work // исходные работы по каталогу подготовленной работы.
+}
+
+// work - исходная работа.
+type work struct {
+	dir   string             // каталог исходной работы.
+	files map[string]fileMap // соответствие файлов по пути к подготовленному файлу.
+}
+
+// fileMap - соответствие подготовленного файла исходному.
+type fileMap struct {
+	source  string // путь к исходному файлу относительно каталога работы.
+	removed []int  // байтовые позиции подготовленного файла, перед которыми удалены символы '\r'.
+	cells   []cell // ячейки блокнота, из которого извлечён подготовленный файл.
+}
+
+// Restore переводит результат анализа подготовленных работ к исходным работам:
+// каталоги работ, пути к файлам и позиции фрагментов.
+// Фрагмент блокнота, захватывающий несколько ячеек, разбивается на совпадения по ячейкам.
+// Части фрагментов двух работ сопоставляются по порядку, последняя часть работы с меньшим числом частей повторяется.
+func (p *Prepared) Restore(items []*checker.ReportItem) error {
+	lines := make(map[string]*checker.LineIndex)
+
+	for _, item := range items {
+		work1, ok1 := p.works[item.Work1Dir]
+		work2, ok2 := p.works[item.Work2Dir]
+
+		matches := make([]checker.MatchItem, 0, len(item.Matches))
+		for _, m := range item.Matches {
+			parts1 := []restored{{file: m.Work1File, start: m.Work1Start, size: m.Work1Size, cell: m.Work1Cell}}
+			parts2 := []restored{{file: m.Work2File, start: m.Work2Start, size: m.Work2Size, cell: m.Work2Cell}}
+
+			var err error
+			if ok1 {
+				if parts1, err = work1.restore(item.Work1Dir, m.Work1File, m.Work1Start, m.Work1Size, item.OffsetUnit, lines); err != nil {
+					return err
+				}
+			}
+			if ok2 {
+				if parts2, err = work2.restore(item.Work2Dir, m.Work2File, m.Work2Start, m.Work2Size, item.OffsetUnit, lines); err != nil {
+					return err
+				}
+			}
+
+			for i := range max(len(parts1), len(parts2)) {
+				r1, r2 := parts1[min(i, len(parts1)-1)], parts2[min(i, len(parts2)-1)]
+				m.Work1File, m.Work1Start, m.Work1Size, m.Work1Cell = r1.file, r1.start, r1.size, r1.cell
+				m.Work2File, m.Work2Start, m.Work2Size, m.Work2Cell = r2.file, r2.start, r2.size, r2.cell
+				matches = append(matches, m)
+			}
+		}
+		item.Matches = matches
+
+		if ok1 {
+			item.Work1Dir = work1.dir
+		}
+		if ok2 {
+			item.Work2Dir = work2.dir
+		}
+	}
+
+	return nil
+}
+
+// Remove удаляет подготовленные работы.
+func (p *Prepared) Remove() error {
+	return os.RemoveAll(p.dir)
+}
+
+// restored - фрагмент исходного файла.
+type restored struct {
+	file        string
+	start, size uint64
+	cell        *uint32 // ячейка блокнота (позиция указана внутри ячейки).
+}
+
+// restore переводит фрагмент подготовленного файла к исходному файлу.
+// Dir: каталог подготовленной работы.
+// Фрагмент блокнота разбивается по ячейкам, позиция каждой части указывается внутри ячейки.
+func (w *work) restore(dir string, file string, start uint64, size uint64, unit checker.OffsetUnit,
+	lines map[string]*checker.LineIndex) ([]restored, error) {
+	m, ok := w.files[file]
+	if !ok {
+		return []restored{{file: file, start: start, size: size}}, nil
+	}
+	if len(m.removed) == 0 && m.cells == nil {
+		return []restored{{file: m.source, start: start, size: size}}, nil
+	}
+
+	prepared, err := lineIndex(lines, path.Join(dir, file))
+	if err != nil {
+		return nil, err
+	}
+
+	if m.cells != nil {
+		parts := splitCells(m.cells, prepared.FromUnit(start, unit), prepared.FromUnit(start+size, unit))
+		if len(parts) == 0 {
+			return []restored{{file: m.source}}, nil
+		}
+
+		result := make([]restored, len(parts))
+		for i, part := range parts {
+			cellStart := prepared.ToUnit(part.cell.start, unit)
+			startUnit := prepared.ToUnit(part.from, unit)
+			result[i] = restored{
+				file:  m.source,
+				start: startUnit - cellStart,
+				size:  prepared.ToUnit(part.to, unit) - startUnit,
+				cell:  &part.cell.index,
+			}
+		}
+		return result, nil
+	}
+
+	source, err := lineIndex(lines, path.Join(w.dir, m.source))
+	if err != nil {
+		return nil, err
+	}
+
+	// Перевод позиций в байты подготовленного файла, затем исходного файла.
+	from := m.sourceOffset(prepared.FromUnit(start, unit))
+	to := m.sourceOffset(prepared.FromUnit(start+size, unit))
+
+	startUnit := source.ToUnit(from, unit)
+	return []restored{{file: m.source, start: startUnit, size: source.ToUnit(to, unit) - startUnit}}, nil
+}
+
+// sourceOffset переводит байтовую позицию подготовленного файла в позицию исходного файла.
+func (m fileMap) sourceOffset(offset int) int {
+	return offset + sort.SearchInts(m.removed, offset)
+}
+
+// lineIndex возвращает индекс строк файла. Индекс строится один раз для всех совпадений.
+func lineIndex(lines map[string]*checker.LineIndex, filePath string) (*checker.LineIndex, error) {
+	if index, ok := lines[filePath]; ok {
+		return index, nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	index := checker.NewLineIndex(content)
+	lines[filePath] = index
+	return index, nil
+}
@@ -0,0 +1,102 @@
+package preprocess
+
+import (
+	"bytes"
+	"path"
+	"strings"
+)
+
+// Шаблоны автоматически сгенерированных файлов и служебных каталогов.
+var generatedPatterns = []string{
+	"bin", "obj", ".vs", ".idea", "Migrations",
+	"*.Designer.cs", "*.designer.cs", "AssemblyInfo.cs", "*.AssemblyInfo.cs",
+	"*.AssemblyAttributes.cs", "*.g.cs", "*.g.i.cs", "*ModelSnapshot.cs",
+}
+
+// Размер начала файла, в котором ищутся нулевые байты.
+const headSize = 8000
+
+// matchGlob проверяет соответствие пути файла относительно каталога работы шаблону.
+// Шаблон без "/" сравнивается с названием файла и каждого каталога пути,
+// шаблон с "/" - с путём целиком. "**" соответствует любому числу каталогов.
+func matchGlob(pattern string, rel string) bool {
+	pattern = strings.Trim(pattern, "/")
+	segments := strings.Split(rel, "/")
+
+	if !strings.Contains(pattern, "/") {
+		for _, segment := range segments {
+			if ok, _ := path.Match(pattern, segment); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), segments)
+}
+
+// matchSegments сопоставляет части шаблона и пути.
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// isGenerated проверяет по названию, что файл сгенерирован автоматически.
+// Метки "<auto-generated>" в содержимом не учитываются: их легко добавить в любой файл.
+func isGenerated(rel string) bool {
+	for _, pattern := range generatedPatterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinary проверяет, что файл бинарный (содержит нулевой байт в начале).
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), headSize)], 0) != -1
+}
+
+// normalizeEOL заменяет переводы строк "\r\n" и "\r" на "\n".
+// Возвращает содержимое и позиции результата, перед которыми удалены символы '\r'.
+func normalizeEOL(content []byte) ([]byte, []int) {
+	if bytes.IndexByte(content, '\r') == -1 {
+		return content, nil
+	}
+
+	result := make([]byte, 0, len(content))
+	var removed []int
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\r' {
+			result = append(result, content[i])
+			continue
+		}
+
+		if i+1 < len(content) && content[i+1] == '\n' {
+			removed = append(removed, len(result))
+			continue
+		}
+		result = append(result, '\n')
+	}
+
+	return result, removed
+}
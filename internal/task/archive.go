@@ -0,0 +1,107 @@
+package task
+
+import (
+	"CodeBorrowing/internal/logger"
+	"CodeBorrowing/internal/utils"
+	"os"
+	"path"
+	"strconv"
+)
+
+// Archive - архив работ прошлых событий.
+// Структура каталога архива повторяет хранилище работ:
+// <root>/<eventID>/<workID>/<workID>/... .
+type Archive interface {
+	// GetWorks получает работы архива, не относящиеся к событию eventID.
+	GetWorks(eventID uint64) ([]ArchiveEntry, error)
+
+	// SaveWorks сохраняет работы события в архив.
+	SaveWorks(eventID uint64, works []WorkEntry) error
+}
+
+type archive struct {
+	logger *logger.Logger
+	root   string
+}
+
+// NewArchive создаёт архив работ прошлых событий.
+// Logger: логгер приложения.
+// Root: путь к каталогу архива.
+func NewArchive(logger *logger.Logger, root string) (Archive, error) {
+	// Создание каталога архива.
+	if _, err := utils.CreateDirectory(root); err != nil {
+		return nil, err
+	}
+
+	return &archive{
+		logger: logger,
+		root:   root,
+	}, nil
+}
+
+// GetWorks получает работы архива, не относящиеся к событию eventID.
+func (a *archive) GetWorks(eventID uint64) ([]ArchiveEntry, error) {
+	events, err := os.ReadDir(a.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ArchiveEntry
+	for _, event := range events {
+		// Каталоги, название которых не является id события, пропускаем.
+		id, err := strconv.ParseUint(event.Name(), 10, 64)
+		if err != nil || !event.IsDir() || id == eventID {
+			continue
+		}
+
+		// Получение работ события.
+		eventPath := path.Join(a.root, event.Name())
+		works, err := os.ReadDir(eventPath)
+		if err != nil {
+			a.logger.Error(err)
+			continue
+		}
+
+		for _, work := range works {
+			workID, err := strconv.ParseUint(work.Name(), 10, 64)
+			if err != nil || !work.IsDir() {
+				continue
+			}
+
+			result = append(result, ArchiveEntry{
+				EventID: id,
+				WorkID:  workID,
+				Path:    path.Join(eventPath, work.Name()),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// SaveWorks сохраняет работы события в архив.
+// Уже сохранённые работы не перезаписываются.
+func (a *archive) SaveWorks(eventID uint64, works []WorkEntry) error {
+	eventPath := path.Join(a.root, strconv.FormatUint(eventID, 10))
+
+	for _, work := range works {
+		workPath := path.Join(eventPath, strconv.FormatUint(work.WorkID, 10))
+
+		// Если работа уже в архиве.
+		existed, err := utils.CreateDirectory(workPath)
+		if err != nil {
+			return err
+		}
+		if existed {
+			continue
+		}
+
+		// Копирование работы в архив.
+		if err = utils.CopyDirectory(work.Path, workPath); err != nil {
+			_ = os.RemoveAll(workPath)
+			return err
+		}
+	}
+
+	return nil
+}
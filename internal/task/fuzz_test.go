@@ -0,0 +1,79 @@
+package task
+
+import (
+	"CodeBorrowing/internal/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// FuzzUnzipWork проверяет распаковку архивов работ: распаковка не завершается паникой
+// и не создаёт файлы за пределами каталога работы.
+// Начальный корпус: testdata/fuzz/FuzzUnzipWork.
+func FuzzUnzipWork(f *testing.F) {
+	s := &service{logger: logger.NewLogger(f.TempDir())}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Каталог работы вложен в каталог теста: файлы вне каталога работы обнаруживаются обходом.
+		dir := t.TempDir()
+		unzipPath := filepath.Join(dir, "works", "1", "1")
+		if err := os.MkdirAll(unzipPath, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		_ = s.unzipWork(data, unzipPath)
+
+		err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode()&os.ModeSymlink != 0 {
+				t.Errorf("создана символическая ссылка %s", filePath)
+			}
+			if filePath == unzipPath || strings.HasPrefix(filePath, unzipPath+string(filepath.Separator)) {
+				return nil
+			}
+			if info.IsDir() && strings.HasPrefix(unzipPath, filePath+string(filepath.Separator)) {
+				return nil // каталог на пути к каталогу работы.
+			}
+			t.Errorf("элемент %s создан за пределами каталога работы", filePath)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestItemPath(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Main.cs", want: "/w/Main.cs"},
+		{name: "src/Main.cs", want: "/w/src/Main.cs"},
+		{name: "src/../Main.cs", want: "/w/Main.cs"},
+		{name: "src/", want: "/w/src"},
+		{name: "./", want: "/w"},
+		{name: `..\evil.cs`, want: `/w/..\evil.cs`}, // в Linux "\" - часть названия файла.
+		{name: "../evil.cs"},
+		{name: "src/../../evil.cs"},
+		{name: ".."},
+		{name: "/etc/passwd"},
+		{name: ""},
+	}
+
+	for _, test := range tests {
+		got, err := itemPath("/w", test.name)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("itemPath(%q) = %q, ожидалась ошибка", test.name, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("itemPath(%q) = %q, %v, ожидалось %q", test.name, got, err, test.want)
+		}
+	}
+}
@@ -12,3 +12,9 @@ type WorkUrl struct {
 	WorkID uint64
 	Url    string
 }
+
+type ArchiveEntry struct {
+	EventID uint64
+	WorkID  uint64
+	Path    string
+}
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"CodeBorrowing/internal/fingerprint"
 	"CodeBorrowing/internal/logger"
 	"CodeBorrowing/internal/utils"
 	"CodeBorrowing/services/orchestrator"
@@ -8,16 +9,23 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"io"
 	"net/http"
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 )
 
 var ErrNoNewTask = errors.New("нет новой задачи")
 var ErrNoWork = errors.New("нет работы")
+var ErrSubscribeUnsupported = errors.New("сервер не поддерживает уведомления о задачах")
+var ErrLeaseUnsupported = errors.New("сервер не поддерживает аренду задач")
 
 type Service interface {
 	// GetNewTasksOfCommonEvent получает новые задачи от сервера из одного события.
@@ -35,6 +43,12 @@ type Service interface {
 	// CloseTaskWithError отправляет сигнал о завершении выполнения задачи.
 	CloseTaskWithError(taskID []uint64) error
 
+	// ExtendTaskLease продлевает аренду выполняемых задач.
+	ExtendTaskLease(taskID []uint64) (lease time.Duration, lost []uint64, err error)
+
+	// SubscribeTasks получает уведомления о новых задачах, пока поток не прервётся или не будет отменён ctx.
+	SubscribeTasks(ctx context.Context, notify func(eventID uint64)) error
+
 	// GetWorkPath - получение пути к каталогу с работой.
 	GetWorkPath(workID uint64) string
 
@@ -45,6 +59,7 @@ type Service interface {
 type service struct {
 	grpcClient orchestrator.OrchestratorClient
 	storage    Storage
+	index      fingerprint.Index
 	logger     *logger.Logger
 	root       string
 	size       uint64
@@ -53,10 +68,11 @@ type service struct {
 // NewService создаёт новый сервис для работы с задачами.
 // GrpcClient: grpc клиент.
 // TaskStorage: хранилище работ студентов.
+// Index: индекс отпечатков работ (nil, если индекс не используется).
 // Logger: логгер приложения.
 // Path: путь к хранилищу работ.
 // Size: лимит заполняемого на диске пространства в Мб.
-func NewService(grpcClient orchestrator.OrchestratorClient, taskStorage Storage,
+func NewService(grpcClient orchestrator.OrchestratorClient, taskStorage Storage, index fingerprint.Index,
 	logger *logger.Logger, path string, size uint64) (Service, error) {
 
 	// Проверка лимита заполняемого на диске пространства
@@ -67,6 +83,7 @@ func NewService(grpcClient orchestrator.OrchestratorClient, taskStorage Storage,
 	return &service{
 		grpcClient: grpcClient,
 		storage:    taskStorage,
+		index:      index,
 		logger:     logger,
 		root:       path,
 		size:       size,
@@ -261,9 +278,14 @@ func (s *service) unzipWork(buf []byte, unzipPath string) error {
 }
 
 // unzipItem разархивирует элемент архива.
+// Элементы, путь которых выходит за пределы каталога unzipPath, пропускаются.
 func (s *service) unzipItem(f *zip.File, unzipPath string) {
 	// Путь элемента архива.
-	newFilePath := path.Join(unzipPath, f.Name)
+	newFilePath, err := itemPath(unzipPath, f.Name)
+	if err != nil {
+		s.logger.Error(err)
+		return
+	}
 
 	// Разархивируем папку.
 	if f.FileInfo().IsDir() {
@@ -273,10 +295,37 @@ func (s *service) unzipItem(f *zip.File, unzipPath string) {
 		return
 	}
 
+	// Только обычные файлы: символические ссылки и другие специальные файлы не создаются.
+	if !f.Mode().IsRegular() {
+		s.logger.Errorf("элемент архива %s пропущен: не является файлом", f.Name)
+		return
+	}
+
+	// Каталог файла, если в архиве нет отдельного элемента для него.
+	if err := os.MkdirAll(path.Dir(newFilePath), os.ModePerm); err != nil {
+		s.logger.Error(err)
+		return
+	}
+
 	// Разархивируем файл.
 	s.unzipFile(f, newFilePath)
 }
 
+// itemPath возвращает путь элемента архива name в каталоге unzipPath.
+// Абсолютные пути и пути с ".." за пределы каталога не допускаются.
+func itemPath(unzipPath string, name string) (string, error) {
+	if name == "" || path.IsAbs(name) {
+		return "", fmt.Errorf("недопустимый путь элемента архива %q", name)
+	}
+
+	rel := path.Clean(name)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("недопустимый путь элемента архива %q", name)
+	}
+
+	return path.Join(unzipPath, rel), nil
+}
+
 // unzipFile разархивирует файл.
 func (s *service) unzipFile(f *zip.File, unzipPath string) {
 	// Создание файла.
@@ -304,6 +353,7 @@ func (s *service) unzipFile(f *zip.File, unzipPath string) {
 // downloadWork скачивает работу по ссылки.
 func (s *service) downloadWork(workID uint64, url string) (WorkEntry, error) {
 	work := WorkEntry{
+		WorkID:    workID,
 		Path:      s.GetWorkPath(workID),
 		Timestamp: time.Now(),
 	}
@@ -333,6 +383,13 @@ func (s *service) downloadWork(workID uint64, url string) (WorkEntry, error) {
 		return work, nil
 	}
 
+	// Обновить индекс отпечатков.
+	if s.index != nil {
+		if err = s.index.Add(workID, work.Path); err != nil {
+			s.logger.Error(err)
+		}
+	}
+
 	return work, nil
 }
 
@@ -397,6 +454,48 @@ func (s *service) CloseTaskWithError(taskID []uint64) error {
 	return nil
 }
 
+// ExtendTaskLease продлевает аренду выполняемых задач. Возвращает время аренды, назначенное сервером
+// (0 - не сообщено), и id задач, аренда которых уже истекла.
+// Возвращает ErrLeaseUnsupported, если сервер не поддерживает аренду задач.
+func (s *service) ExtendTaskLease(taskID []uint64) (time.Duration, []uint64, error) {
+	resp, err := s.grpcClient.ExtendTaskLease(context.Background(), &orchestrator.ExtendTaskLeaseRequest{
+		ID: taskID,
+	})
+	if status.Code(err) == codes.Unimplemented {
+		return 0, nil, ErrLeaseUnsupported
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return time.Duration(resp.GetLeaseSeconds()) * time.Second, resp.GetLostID(), nil
+}
+
+// SubscribeTasks получает уведомления о новых задачах из потока сервера и вызывает notify для каждого.
+// Возвращает ErrSubscribeUnsupported, если сервер не поддерживает поток уведомлений.
+func (s *service) SubscribeTasks(ctx context.Context, notify func(eventID uint64)) error {
+	stream, err := s.grpcClient.SubscribeTasks(ctx, &emptypb.Empty{})
+	if err != nil {
+		return subscribeError(err)
+	}
+
+	for {
+		notification, err := stream.Recv()
+		if err != nil {
+			return subscribeError(err)
+		}
+		notify(notification.GetEventID())
+	}
+}
+
+// subscribeError возвращает ошибку потока уведомлений.
+func subscribeError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return ErrSubscribeUnsupported
+	}
+	return err
+}
+
 // removeOldWorks удаление старых работ.
 func (s *service) removeOldWorks() (uint64, error) {
 	// Получение последних 10 работ.
@@ -437,6 +536,13 @@ func (s *service) removeOldWorks() (uint64, error) {
 		return removed, nil
 	}
 
+	// Удаление работ из индекса отпечатков.
+	if s.index != nil {
+		if err = s.index.Remove(ids); err != nil {
+			s.logger.Error(err)
+		}
+	}
+
 	return removed, nil
 }
 
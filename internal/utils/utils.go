@@ -2,9 +2,11 @@ package utils
 
 import (
 	"errors"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // CreateDirectory создаёт каталог по указанному пути.
@@ -69,3 +71,97 @@ func GetDirectorySize(path string) (uint64, error) {
 
 	return uint64(size), err
 }
+
+// CopyDirectory рекурсивно копирует каталог src в каталог dst.
+func CopyDirectory(src string, dst string) error {
+	return filepath.Walk(src, func(srcPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Путь к элементу в новом каталоге.
+		rel, err := filepath.Rel(src, srcPath)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, rel)
+
+		// Если каталог.
+		if info.IsDir() {
+			return os.MkdirAll(dstPath, os.ModePerm)
+		}
+
+		return CopyFile(srcPath, dstPath)
+	})
+}
+
+// CopyFile копирует файл src в файл dst.
+func CopyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err = io.Copy(out, in); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveOldEntries удаляет из подкаталогов каталога path файлы и каталоги, изменённые раньше limit назад.
+// Опустевшие подкаталоги удаляются. Если каталога path нет, ничего не делает.
+func RemoveOldEntries(path string, limit time.Duration) error {
+	dirs, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	exp := time.Now().Add(-limit)
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		dirPath := filepath.Join(path, dir.Name())
+
+		entries, err := os.ReadDir(dirPath)
+		if err != nil {
+			return err
+		}
+
+		removed := 0
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil {
+				return err
+			}
+
+			// Если объект старый - удаляем.
+			if info.ModTime().Before(exp) {
+				if err = os.RemoveAll(filepath.Join(dirPath, e.Name())); err != nil {
+					return err
+				}
+				removed++
+			}
+		}
+
+		// Удаление опустевшего подкаталога.
+		if removed == len(entries) {
+			if err = os.Remove(dirPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+import java.io.BufferedReader;
+import java.io.File;
+import java.io.FileDescriptor;
+import java.io.FileOutputStream;
+import java.io.InputStreamReader;
+import java.io.PrintStream;
+import java.lang.reflect.Constructor;
+import java.lang.reflect.InvocationTargetException;
+import java.lang.reflect.Method;
+import java.lang.reflect.Modifier;
+import java.nio.charset.StandardCharsets;
+import java.util.HashMap;
+import java.util.LinkedHashSet;
+import java.util.Map;
+import java.util.ServiceLoader;
+import java.util.Set;
+
+/**
+ * Постоянный процесс Jplag: принимает команды запуска анализа через stdin
+ * и выполняет анализ через API Jplag в той же JVM, чтобы не платить за запуск JVM на каждый анализ.
+ *
+ * API вызывается напрямую, а не через CLI Jplag: CLI завершает JVM (System.exit) при ошибке анализа.
+ * Параметры анализа - значения JPlagOptions по умолчанию, как у CLI, отчёт сохраняется так же, как CLI
+ * той же версии, поэтому результат совпадает с запуском отдельного процесса (java -jar jplag.jar).
+ * Классы Jplag загружаются по названиям, чтобы процесс работал с Jplag 4.x - 6.x без перекомпиляции.
+ *
+ * Протокол (UTF-8, построчно):
+ *   процесс -> раннер: "READY" после запуска;
+ *   раннер -> процесс: "RUN <n>", затем n строк - аргументы командной строки Jplag:
+ *     -new <каталоги через запятую> -l <язык> -r <результирующий архив> [-old <каталоги через запятую>];
+ *   процесс -> раннер: "OK" или "ERROR <сообщение>" (ошибка анализа, процесс продолжает работу).
+ * Если API Jplag не найден, процесс завершается без "READY".
+ *
+ * Запуск: java -cp jplag.jar:jplag-worker.jar JplagWorker
+ */
+public final class JplagWorker {
+    private final Map<String, Object> languages = new HashMap<>(); // языки по идентификатору.
+    private final Constructor<?> options;                         // JPlagOptions(язык, новые, старые каталоги).
+    private final Method jplagRun;                                // JPlag.run(options) или JPlag.run().
+    private final Constructor<?> jplag;                           // JPlag(options) для Jplag 4.x.
+    private final Constructor<?> reportFactory;                   // ReportObjectFactory(File) или ReportObjectFactory().
+    private final Method saveReport;                              // createAndSaveReport(result[, путь]).
+
+    public static void main(String[] args) throws Exception {
+        // Протокол использует исходный stdout, вывод Jplag перенаправляется в stderr.
+        PrintStream protocol = new PrintStream(new FileOutputStream(FileDescriptor.out), true, StandardCharsets.UTF_8);
+        System.setOut(System.err);
+
+        JplagWorker worker = new JplagWorker();
+        BufferedReader in = new BufferedReader(new InputStreamReader(System.in, StandardCharsets.UTF_8));
+
+        protocol.println("READY");
+
+        String line;
+        while ((line = in.readLine()) != null) {
+            if (!line.startsWith("RUN ")) {
+                protocol.println("ERROR неизвестная команда: " + line);
+                continue;
+            }
+
+            // Чтение аргументов.
+            String[] cliArgs = new String[Integer.parseInt(line.substring(4).trim())];
+            for (int i = 0; i < cliArgs.length; i++) {
+                cliArgs[i] = in.readLine();
+            }
+
+            // Запуск анализа.
+            try {
+                worker.run(cliArgs);
+                protocol.println("OK");
+            } catch (InvocationTargetException e) {
+                protocol.println("ERROR " + message(e.getCause()));
+            } catch (Exception e) {
+                protocol.println("ERROR " + message(e));
+            }
+        }
+    }
+
+    // Поиск языков и методов API Jplag.
+    private JplagWorker() throws ReflectiveOperationException {
+        Class<?> languageClass = Class.forName("de.jplag.Language");
+        Method identifier = languageClass.getMethod("getIdentifier");
+        for (Object language : ServiceLoader.load(languageClass)) {
+            languages.put((String) identifier.invoke(language), language);
+        }
+
+        Class<?> optionsClass = Class.forName("de.jplag.options.JPlagOptions");
+        options = optionsClass.getConstructor(languageClass, Set.class, Set.class);
+
+        // Jplag 5.x - 6.x: статический JPlag.run(options), Jplag 4.x: new JPlag(options).run().
+        Class<?> jplagClass = Class.forName("de.jplag.JPlag");
+        Method staticRun = null;
+        try {
+            staticRun = jplagClass.getMethod("run", optionsClass);
+        } catch (NoSuchMethodException e) {
+            // Jplag 4.x.
+        }
+        if (staticRun != null && Modifier.isStatic(staticRun.getModifiers())) {
+            jplagRun = staticRun;
+            jplag = null;
+        } else {
+            jplagRun = jplagClass.getMethod("run");
+            jplag = jplagClass.getConstructor(optionsClass);
+        }
+
+        // Jplag 5.x - 6.x: new ReportObjectFactory(файл).createAndSaveReport(result),
+        // Jplag 4.x: new ReportObjectFactory().createAndSaveReport(result, путь).
+        Class<?> resultClass = Class.forName("de.jplag.JPlagResult");
+        Class<?> factoryClass = Class.forName("de.jplag.reporting.reportobject.ReportObjectFactory");
+        Constructor<?> fileFactory = null;
+        try {
+            fileFactory = factoryClass.getConstructor(File.class);
+        } catch (NoSuchMethodException e) {
+            // Jplag 4.x.
+        }
+        if (fileFactory != null) {
+            reportFactory = fileFactory;
+            saveReport = factoryClass.getMethod("createAndSaveReport", resultClass);
+        } else {
+            reportFactory = factoryClass.getConstructor();
+            saveReport = factoryClass.getMethod("createAndSaveReport", resultClass, String.class);
+        }
+    }
+
+    // Выполнение анализа с аргументами командной строки Jplag.
+    private void run(String[] args) throws ReflectiveOperationException {
+        Set<File> newDirectories = new LinkedHashSet<>();
+        Set<File> oldDirectories = new LinkedHashSet<>();
+        String languageName = null;
+        String resultPath = null;
+
+        if (args.length % 2 != 0) {
+            throw new IllegalArgumentException("не указано значение аргумента " + args[args.length - 1]);
+        }
+        for (int i = 0; i < args.length; i += 2) {
+            switch (args[i]) {
+                case "-new" -> addDirectories(newDirectories, args[i + 1]);
+                case "-old" -> addDirectories(oldDirectories, args[i + 1]);
+                case "-l" -> languageName = args[i + 1];
+                case "-r" -> resultPath = args[i + 1];
+                default -> throw new IllegalArgumentException("неизвестный аргумент " + args[i]);
+            }
+        }
+
+        Object language = languages.get(languageName);
+        if (language == null) {
+            throw new IllegalArgumentException("неизвестный язык " + languageName);
+        }
+        if (resultPath == null) {
+            throw new IllegalArgumentException("не указан результирующий архив (-r)");
+        }
+
+        // Анализ.
+        Object jplagOptions = options.newInstance(language, newDirectories, oldDirectories);
+        Object result = jplag == null
+                ? jplagRun.invoke(null, jplagOptions)
+                : jplagRun.invoke(jplag.newInstance(jplagOptions));
+
+        // Сохранение отчёта, как в CLI: Jplag 5.x - 6.x дописывает расширение .zip.
+        if (reportFactory.getParameterCount() == 1) {
+            if (!resultPath.endsWith(".zip")) {
+                resultPath += ".zip";
+            }
+            saveReport.invoke(reportFactory.newInstance(new File(resultPath)), result);
+        } else {
+            saveReport.invoke(reportFactory.newInstance(), result, resultPath);
+        }
+    }
+
+    // Добавление каталогов из списка через запятую.
+    private static void addDirectories(Set<File> directories, String value) {
+        for (String directory : value.split(",")) {
+            if (!directory.isEmpty()) {
+                directories.add(new File(directory));
+            }
+        }
+    }
+
+    // Сообщение об ошибке в одну строку.
+    private static String message(Throwable e) {
+        String message = e == null ? "" : e.toString();
+        return message.replace('\r', ' ').replace('\n', ' ');
+    }
+}
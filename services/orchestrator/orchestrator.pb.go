@@ -22,6 +22,55 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type OffsetUnit int32
+
+const (
+	OffsetUnit_OFFSET_UNIT_RUNES OffsetUnit = 0
+	OffsetUnit_OFFSET_UNIT_BYTES OffsetUnit = 1
+	OffsetUnit_OFFSET_UNIT_UTF16 OffsetUnit = 2
+)
+
+// Enum value maps for OffsetUnit.
+var (
+	OffsetUnit_name = map[int32]string{
+		0: "OFFSET_UNIT_RUNES",
+		1: "OFFSET_UNIT_BYTES",
+		2: "OFFSET_UNIT_UTF16",
+	}
+	OffsetUnit_value = map[string]int32{
+		"OFFSET_UNIT_RUNES": 0,
+		"OFFSET_UNIT_BYTES": 1,
+		"OFFSET_UNIT_UTF16": 2,
+	}
+)
+
+func (x OffsetUnit) Enum() *OffsetUnit {
+	p := new(OffsetUnit)
+	*p = x
+	return p
+}
+
+func (x OffsetUnit) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OffsetUnit) Descriptor() protoreflect.EnumDescriptor {
+	return file_orchestrator_proto_enumTypes[0].Descriptor()
+}
+
+func (OffsetUnit) Type() protoreflect.EnumType {
+	return &file_orchestrator_proto_enumTypes[0]
+}
+
+func (x OffsetUnit) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OffsetUnit.Descriptor instead.
+func (OffsetUnit) EnumDescriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{0}
+}
+
 type Task struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ID            uint64                 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
@@ -614,6 +663,8 @@ type SendCrossCheckReportMatches struct {
 	SecondWorkPath  string                 `protobuf:"bytes,4,opt,name=secondWorkPath,proto3" json:"secondWorkPath,omitempty"`
 	SecondWorkStart uint64                 `protobuf:"varint,5,opt,name=secondWorkStart,proto3" json:"secondWorkStart,omitempty"`
 	SecondWorkSize  uint64                 `protobuf:"varint,6,opt,name=secondWorkSize,proto3" json:"secondWorkSize,omitempty"`
+	FirstWorkCell   *uint32                `protobuf:"varint,7,opt,name=firstWorkCell,proto3,oneof" json:"firstWorkCell,omitempty"`
+	SecondWorkCell  *uint32                `protobuf:"varint,8,opt,name=secondWorkCell,proto3,oneof" json:"secondWorkCell,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -690,13 +741,30 @@ func (x *SendCrossCheckReportMatches) GetSecondWorkSize() uint64 {
 	return 0
 }
 
+func (x *SendCrossCheckReportMatches) GetFirstWorkCell() uint32 {
+	if x != nil && x.FirstWorkCell != nil {
+		return *x.FirstWorkCell
+	}
+	return 0
+}
+
+func (x *SendCrossCheckReportMatches) GetSecondWorkCell() uint32 {
+	if x != nil && x.SecondWorkCell != nil {
+		return *x.SecondWorkCell
+	}
+	return 0
+}
+
 type SendCrossCheckReportRequest struct {
-	state         protoimpl.MessageState         `protogen:"open.v1"`
-	FirstWorkID   uint64                         `protobuf:"varint,1,opt,name=firstWorkID,proto3" json:"firstWorkID,omitempty"`
-	SecondWorkID  uint64                         `protobuf:"varint,2,opt,name=secondWorkID,proto3" json:"secondWorkID,omitempty"`
-	Match         []*SendCrossCheckReportMatches `protobuf:"bytes,3,rep,name=match,proto3" json:"match,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState         `protogen:"open.v1"`
+	FirstWorkID       uint64                         `protobuf:"varint,1,opt,name=firstWorkID,proto3" json:"firstWorkID,omitempty"`
+	SecondWorkID      uint64                         `protobuf:"varint,2,opt,name=secondWorkID,proto3" json:"secondWorkID,omitempty"`
+	Match             []*SendCrossCheckReportMatches `protobuf:"bytes,3,rep,name=match,proto3" json:"match,omitempty"`
+	FirstWorkEventID  uint64                         `protobuf:"varint,4,opt,name=firstWorkEventID,proto3" json:"firstWorkEventID,omitempty"`
+	SecondWorkEventID uint64                         `protobuf:"varint,5,opt,name=secondWorkEventID,proto3" json:"secondWorkEventID,omitempty"`
+	OffsetUnit        OffsetUnit                     `protobuf:"varint,6,opt,name=offsetUnit,proto3,enum=OffsetUnit" json:"offsetUnit,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *SendCrossCheckReportRequest) Reset() {
@@ -750,6 +818,27 @@ func (x *SendCrossCheckReportRequest) GetMatch() []*SendCrossCheckReportMatches
 	return nil
 }
 
+func (x *SendCrossCheckReportRequest) GetFirstWorkEventID() uint64 {
+	if x != nil {
+		return x.FirstWorkEventID
+	}
+	return 0
+}
+
+func (x *SendCrossCheckReportRequest) GetSecondWorkEventID() uint64 {
+	if x != nil {
+		return x.SecondWorkEventID
+	}
+	return 0
+}
+
+func (x *SendCrossCheckReportRequest) GetOffsetUnit() OffsetUnit {
+	if x != nil {
+		return x.OffsetUnit
+	}
+	return OffsetUnit_OFFSET_UNIT_RUNES
+}
+
 type SendDefaultReportSegment struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	WorkPath      string                 `protobuf:"bytes,1,opt,name=workPath,proto3" json:"workPath,omitempty"`
@@ -870,6 +959,274 @@ func (x *SendDefaultReportRequest) GetSegment() []*SendDefaultReportSegment {
 	return nil
 }
 
+type TaskNotification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventID       uint64                 `protobuf:"varint,1,opt,name=eventID,proto3" json:"eventID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskNotification) Reset() {
+	*x = TaskNotification{}
+	mi := &file_orchestrator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskNotification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskNotification) ProtoMessage() {}
+
+func (x *TaskNotification) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskNotification.ProtoReflect.Descriptor instead.
+func (*TaskNotification) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TaskNotification) GetEventID() uint64 {
+	if x != nil {
+		return x.EventID
+	}
+	return 0
+}
+
+type ExtendTaskLeaseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ID            []uint64               `protobuf:"varint,1,rep,packed,name=ID,proto3" json:"ID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendTaskLeaseRequest) Reset() {
+	*x = ExtendTaskLeaseRequest{}
+	mi := &file_orchestrator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendTaskLeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendTaskLeaseRequest) ProtoMessage() {}
+
+func (x *ExtendTaskLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendTaskLeaseRequest.ProtoReflect.Descriptor instead.
+func (*ExtendTaskLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExtendTaskLeaseRequest) GetID() []uint64 {
+	if x != nil {
+		return x.ID
+	}
+	return nil
+}
+
+type ExtendTaskLeaseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LeaseSeconds  uint32                 `protobuf:"varint,1,opt,name=leaseSeconds,proto3" json:"leaseSeconds,omitempty"`
+	LostID        []uint64               `protobuf:"varint,2,rep,packed,name=lostID,proto3" json:"lostID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendTaskLeaseResponse) Reset() {
+	*x = ExtendTaskLeaseResponse{}
+	mi := &file_orchestrator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendTaskLeaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendTaskLeaseResponse) ProtoMessage() {}
+
+func (x *ExtendTaskLeaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendTaskLeaseResponse.ProtoReflect.Descriptor instead.
+func (*ExtendTaskLeaseResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ExtendTaskLeaseResponse) GetLeaseSeconds() uint32 {
+	if x != nil {
+		return x.LeaseSeconds
+	}
+	return 0
+}
+
+func (x *ExtendTaskLeaseResponse) GetLostID() []uint64 {
+	if x != nil {
+		return x.LostID
+	}
+	return nil
+}
+
+type CheckerEngine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Language      []string               `protobuf:"bytes,3,rep,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckerEngine) Reset() {
+	*x = CheckerEngine{}
+	mi := &file_orchestrator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckerEngine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckerEngine) ProtoMessage() {}
+
+func (x *CheckerEngine) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckerEngine.ProtoReflect.Descriptor instead.
+func (*CheckerEngine) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CheckerEngine) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CheckerEngine) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *CheckerEngine) GetLanguage() []string {
+	if x != nil {
+		return x.Language
+	}
+	return nil
+}
+
+type RegisterRunnerCapabilitiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Engine        []*CheckerEngine       `protobuf:"bytes,2,rep,name=engine,proto3" json:"engine,omitempty"`
+	Concurrency   uint32                 `protobuf:"varint,3,opt,name=concurrency,proto3" json:"concurrency,omitempty"`
+	OffsetUnit    OffsetUnit             `protobuf:"varint,4,opt,name=offsetUnit,proto3,enum=OffsetUnit" json:"offsetUnit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRunnerCapabilitiesRequest) Reset() {
+	*x = RegisterRunnerCapabilitiesRequest{}
+	mi := &file_orchestrator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRunnerCapabilitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRunnerCapabilitiesRequest) ProtoMessage() {}
+
+func (x *RegisterRunnerCapabilitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRunnerCapabilitiesRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRunnerCapabilitiesRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RegisterRunnerCapabilitiesRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RegisterRunnerCapabilitiesRequest) GetEngine() []*CheckerEngine {
+	if x != nil {
+		return x.Engine
+	}
+	return nil
+}
+
+func (x *RegisterRunnerCapabilitiesRequest) GetConcurrency() uint32 {
+	if x != nil {
+		return x.Concurrency
+	}
+	return 0
+}
+
+func (x *RegisterRunnerCapabilitiesRequest) GetOffsetUnit() OffsetUnit {
+	if x != nil {
+		return x.OffsetUnit
+	}
+	return OffsetUnit_OFFSET_UNIT_RUNES
+}
+
 var File_orchestrator_proto protoreflect.FileDescriptor
 
 var file_orchestrator_proto_rawDesc = string([]byte{
@@ -925,7 +1282,7 @@ var file_orchestrator_proto_rawDesc = string([]byte{
 	0x28, 0x0b, 0x32, 0x05, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x04, 0x74, 0x61, 0x73, 0x6b, 0x22,
 	0x22, 0x0a, 0x10, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x03, 0x28, 0x04, 0x52,
-	0x02, 0x49, 0x44, 0x22, 0x8b, 0x02, 0x0a, 0x1b, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72, 0x6f, 0x73,
+	0x02, 0x49, 0x44, 0x22, 0x88, 0x03, 0x0a, 0x1b, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72, 0x6f, 0x73,
 	0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x74, 0x63,
 	0x68, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x66, 0x69, 0x72, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b,
 	0x50, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x66, 0x69, 0x72, 0x73,
@@ -942,76 +1299,139 @@ var file_orchestrator_proto_rawDesc = string([]byte{
 	0x57, 0x6f, 0x72, 0x6b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x65, 0x63,
 	0x6f, 0x6e, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
 	0x04, 0x52, 0x0e, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x53, 0x69, 0x7a,
-	0x65, 0x22, 0x97, 0x01, 0x0a, 0x1b, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x43,
-	0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x20, 0x0a, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x49, 0x44,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x57, 0x6f, 0x72,
-	0x6b, 0x49, 0x44, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f, 0x72,
-	0x6b, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x73, 0x65, 0x63, 0x6f, 0x6e,
-	0x64, 0x57, 0x6f, 0x72, 0x6b, 0x49, 0x44, 0x12, 0x32, 0x0a, 0x05, 0x6d, 0x61, 0x74, 0x63, 0x68,
-	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72, 0x6f,
-	0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x74,
-	0x63, 0x68, 0x65, 0x73, 0x52, 0x05, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x22, 0x8c, 0x01, 0x0a, 0x18,
-	0x53, 0x65, 0x6e, 0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72,
-	0x74, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x6f, 0x72, 0x6b,
-	0x50, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b,
-	0x50, 0x61, 0x74, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x77, 0x6f, 0x72, 0x6b, 0x53, 0x74, 0x61, 0x72,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x77, 0x6f, 0x72, 0x6b, 0x53, 0x74, 0x61,
-	0x72, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1a,
-	0x0a, 0x08, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02,
-	0x52, 0x08, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x22, 0x67, 0x0a, 0x18, 0x53, 0x65,
-	0x6e, 0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x44,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x44, 0x12, 0x33,
-	0x0a, 0x07, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x19, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x70,
-	0x6f, 0x72, 0x74, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07, 0x73, 0x65, 0x67, 0x6d,
-	0x65, 0x6e, 0x74, 0x32, 0x8f, 0x05, 0x0a, 0x0c, 0x4f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72,
-	0x61, 0x74, 0x6f, 0x72, 0x12, 0x3f, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x52, 0x75, 0x6e, 0x6e, 0x65,
-	0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e,
-	0x47, 0x65, 0x74, 0x52, 0x75, 0x6e, 0x6e, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x4e, 0x65, 0x77, 0x54,
-	0x61, 0x73, 0x6b, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x47, 0x65,
-	0x74, 0x4e, 0x65, 0x77, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x56, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x4e, 0x65, 0x77, 0x54, 0x61, 0x73,
-	0x6b, 0x73, 0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1d, 0x2e, 0x47, 0x65, 0x74, 0x41,
+	0x65, 0x12, 0x29, 0x0a, 0x0d, 0x66, 0x69, 0x72, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x43, 0x65,
+	0x6c, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x0d, 0x66, 0x69, 0x72, 0x73,
+	0x74, 0x57, 0x6f, 0x72, 0x6b, 0x43, 0x65, 0x6c, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0e,
+	0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x43, 0x65, 0x6c, 0x6c, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0d, 0x48, 0x01, 0x52, 0x0e, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f,
+	0x72, 0x6b, 0x43, 0x65, 0x6c, 0x6c, 0x88, 0x01, 0x01, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x66, 0x69,
+	0x72, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x43, 0x65, 0x6c, 0x6c, 0x42, 0x11, 0x0a, 0x0f, 0x5f,
+	0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x43, 0x65, 0x6c, 0x6c, 0x22, 0x9e,
+	0x02, 0x0a, 0x1b, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20,
+	0x0a, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x49, 0x44, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x49, 0x44,
+	0x12, 0x22, 0x0a, 0x0c, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x49, 0x44,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f,
+	0x72, 0x6b, 0x49, 0x44, 0x12, 0x32, 0x0a, 0x05, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x52, 0x05, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x2a, 0x0a, 0x10, 0x66, 0x69, 0x72, 0x73,
+	0x74, 0x57, 0x6f, 0x72, 0x6b, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x10, 0x66, 0x69, 0x72, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x49, 0x44, 0x12, 0x2c, 0x0a, 0x11, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f,
+	0x72, 0x6b, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x11, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x49, 0x44, 0x12, 0x2b, 0x0a, 0x0a, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x55, 0x6e, 0x69, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x55,
+	0x6e, 0x69, 0x74, 0x52, 0x0a, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x55, 0x6e, 0x69, 0x74, 0x22,
+	0x8c, 0x01, 0x0a, 0x18, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08,
+	0x77, 0x6f, 0x72, 0x6b, 0x50, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x77, 0x6f, 0x72, 0x6b, 0x50, 0x61, 0x74, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x77, 0x6f, 0x72, 0x6b,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x77, 0x6f, 0x72,
+	0x6b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x53, 0x69,
+	0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x53, 0x69,
+	0x7a, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x02, 0x52, 0x08, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x22, 0x67,
+	0x0a, 0x18, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x6f,
+	0x72, 0x6b, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x77, 0x6f, 0x72, 0x6b,
+	0x49, 0x44, 0x12, 0x33, 0x0a, 0x07, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c,
+	0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x07,
+	0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x2c, 0x0a, 0x10, 0x54, 0x61, 0x73, 0x6b, 0x4e,
+	0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x49, 0x44, 0x22, 0x28, 0x0a, 0x16, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x54,
+	0x61, 0x73, 0x6b, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x03, 0x28, 0x04, 0x52, 0x02, 0x49, 0x44, 0x22,
+	0x55, 0x0a, 0x17, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x54, 0x61, 0x73, 0x6b, 0x4c, 0x65, 0x61,
+	0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0c, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x6c, 0x6f, 0x73, 0x74, 0x49, 0x44, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52, 0x06,
+	0x6c, 0x6f, 0x73, 0x74, 0x49, 0x44, 0x22, 0x59, 0x0a, 0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x65,
+	0x72, 0x45, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67,
+	0x65, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67,
+	0x65, 0x22, 0xb4, 0x01, 0x0a, 0x21, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x75,
+	0x6e, 0x6e, 0x65, 0x72, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x26, 0x0a, 0x06, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x72, 0x45, 0x6e, 0x67, 0x69, 0x6e,
+	0x65, 0x52, 0x06, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b,
+	0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x2b, 0x0a, 0x0a, 0x6f,
+	0x66, 0x66, 0x73, 0x65, 0x74, 0x55, 0x6e, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0b, 0x2e, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x0a, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x55, 0x6e, 0x69, 0x74, 0x2a, 0x51, 0x0a, 0x0a, 0x4f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x15, 0x0a, 0x11, 0x4f, 0x46, 0x46, 0x53, 0x45, 0x54,
+	0x5f, 0x55, 0x4e, 0x49, 0x54, 0x5f, 0x52, 0x55, 0x4e, 0x45, 0x53, 0x10, 0x00, 0x12, 0x15, 0x0a,
+	0x11, 0x4f, 0x46, 0x46, 0x53, 0x45, 0x54, 0x5f, 0x55, 0x4e, 0x49, 0x54, 0x5f, 0x42, 0x59, 0x54,
+	0x45, 0x53, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x4f, 0x46, 0x46, 0x53, 0x45, 0x54, 0x5f, 0x55,
+	0x4e, 0x49, 0x54, 0x5f, 0x55, 0x54, 0x46, 0x31, 0x36, 0x10, 0x02, 0x32, 0xee, 0x06, 0x0a, 0x0c,
+	0x4f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x3f, 0x0a, 0x0d,
+	0x47, 0x65, 0x74, 0x52, 0x75, 0x6e, 0x6e, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x75, 0x6e, 0x6e, 0x65,
+	0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a,
+	0x0a, 0x47, 0x65, 0x74, 0x4e, 0x65, 0x77, 0x54, 0x61, 0x73, 0x6b, 0x12, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x47, 0x65, 0x74, 0x4e, 0x65, 0x77, 0x54, 0x61, 0x73, 0x6b,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x41,
 	0x6c, 0x6c, 0x4e, 0x65, 0x77, 0x54, 0x61, 0x73, 0x6b, 0x73, 0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c,
-	0x6c, 0x4e, 0x65, 0x77, 0x54, 0x61, 0x73, 0x6b, 0x73, 0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x09, 0x43, 0x6c, 0x6f, 0x73,
-	0x65, 0x54, 0x61, 0x73, 0x6b, 0x12, 0x11, 0x2e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x61, 0x73,
-	0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
-	0x12, 0x3f, 0x0a, 0x12, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x61, 0x73, 0x6b, 0x57, 0x69, 0x74,
-	0x68, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x11, 0x2e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x61,
-	0x73, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
-	0x79, 0x12, 0x44, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x4f, 0x66, 0x45,
-	0x76, 0x65, 0x6e, 0x74, 0x12, 0x17, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x4f,
-	0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
-	0x47, 0x65, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x57, 0x6f,
+	0x74, 0x12, 0x1d, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x4e, 0x65, 0x77, 0x54, 0x61, 0x73,
+	0x6b, 0x73, 0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1e, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x4e, 0x65, 0x77, 0x54, 0x61, 0x73, 0x6b,
+	0x73, 0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x36, 0x0a, 0x09, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x61, 0x73, 0x6b, 0x12, 0x11, 0x2e,
+	0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3f, 0x0a, 0x12, 0x43, 0x6c, 0x6f, 0x73,
+	0x65, 0x54, 0x61, 0x73, 0x6b, 0x57, 0x69, 0x74, 0x68, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x11,
+	0x2e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x44, 0x0a, 0x0f, 0x47, 0x65, 0x74,
+	0x57, 0x6f, 0x72, 0x6b, 0x73, 0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x17, 0x2e, 0x47,
+	0x65, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73,
+	0x4f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x56, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x44, 0x6f, 0x77, 0x6e, 0x6c,
+	0x6f, 0x61, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x1d, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x6f,
 	0x72, 0x6b, 0x73, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73,
-	0x12, 0x1d, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x44, 0x6f, 0x77, 0x6e, 0x6c,
-	0x6f, 0x61, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x1e, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f,
-	0x61, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x4c, 0x0a, 0x14, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63,
-	0x6b, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1c, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72,
-	0x6f, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x46, 0x0a,
-	0x11, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x70, 0x6f,
-	0x72, 0x74, 0x12, 0x19, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x6f, 0x72,
+	0x6b, 0x73, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x14, 0x53, 0x65, 0x6e, 0x64, 0x43,
+	0x72, 0x6f, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x1c, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x43, 0x72, 0x6f, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b,
 	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
 	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x29, 0x5a, 0x27, 0x53, 0x70, 0x61, 0x72, 0x6b, 0x47, 0x75,
-	0x61, 0x72, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x73, 0x2f, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x6f, 0x72,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x46, 0x0a, 0x11, 0x53, 0x65, 0x6e, 0x64, 0x44, 0x65, 0x66,
+	0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x19, 0x2e, 0x53, 0x65, 0x6e,
+	0x64, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3d, 0x0a,
+	0x0e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x54, 0x61, 0x73, 0x6b, 0x73, 0x12,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x11, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x4e, 0x6f,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x30, 0x01, 0x12, 0x44, 0x0a, 0x0f,
+	0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x54, 0x61, 0x73, 0x6b, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x12,
+	0x17, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x54, 0x61, 0x73, 0x6b, 0x4c, 0x65, 0x61, 0x73,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x6e,
+	0x64, 0x54, 0x61, 0x73, 0x6b, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x58, 0x0a, 0x1a, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x75,
+	0x6e, 0x6e, 0x65, 0x72, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73,
+	0x12, 0x22, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x75, 0x6e, 0x6e, 0x65,
+	0x72, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x29, 0x5a, 0x27,
+	0x53, 0x70, 0x61, 0x72, 0x6b, 0x47, 0x75, 0x61, 0x72, 0x64, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x2f, 0x6f, 0x72, 0x63, 0x68, 0x65,
+	0x73, 0x74, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 })
 
 var (
@@ -1026,56 +1446,72 @@ func file_orchestrator_proto_rawDescGZIP() []byte {
 	return file_orchestrator_proto_rawDescData
 }
 
-var file_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_orchestrator_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_orchestrator_proto_goTypes = []any{
-	(*Task)(nil),                              // 0: Task
-	(*Runner)(nil),                            // 1: Runner
-	(*GetRunnerInfoResponse)(nil),             // 2: GetRunnerInfoResponse
-	(*GetWorksOfEventRequest)(nil),            // 3: GetWorksOfEventRequest
-	(*GetWorksOfEventResponse)(nil),           // 4: GetWorksOfEventResponse
-	(*GetWorksDownloadLinksRequest)(nil),      // 5: GetWorksDownloadLinksRequest
-	(*GetWorksDownloadLinksResponseItem)(nil), // 6: GetWorksDownloadLinksResponseItem
-	(*GetWorksDownloadLinksResponse)(nil),     // 7: GetWorksDownloadLinksResponse
-	(*GetAllNewTasksOfEventRequest)(nil),      // 8: GetAllNewTasksOfEventRequest
-	(*GetAllNewTasksOfEventResponse)(nil),     // 9: GetAllNewTasksOfEventResponse
-	(*GetNewTaskResponse)(nil),                // 10: GetNewTaskResponse
-	(*CloseTaskRequest)(nil),                  // 11: CloseTaskRequest
-	(*SendCrossCheckReportMatches)(nil),       // 12: SendCrossCheckReportMatches
-	(*SendCrossCheckReportRequest)(nil),       // 13: SendCrossCheckReportRequest
-	(*SendDefaultReportSegment)(nil),          // 14: SendDefaultReportSegment
-	(*SendDefaultReportRequest)(nil),          // 15: SendDefaultReportRequest
-	(*emptypb.Empty)(nil),                     // 16: google.protobuf.Empty
+	(OffsetUnit)(0),                           // 0: OffsetUnit
+	(*Task)(nil),                              // 1: Task
+	(*Runner)(nil),                            // 2: Runner
+	(*GetRunnerInfoResponse)(nil),             // 3: GetRunnerInfoResponse
+	(*GetWorksOfEventRequest)(nil),            // 4: GetWorksOfEventRequest
+	(*GetWorksOfEventResponse)(nil),           // 5: GetWorksOfEventResponse
+	(*GetWorksDownloadLinksRequest)(nil),      // 6: GetWorksDownloadLinksRequest
+	(*GetWorksDownloadLinksResponseItem)(nil), // 7: GetWorksDownloadLinksResponseItem
+	(*GetWorksDownloadLinksResponse)(nil),     // 8: GetWorksDownloadLinksResponse
+	(*GetAllNewTasksOfEventRequest)(nil),      // 9: GetAllNewTasksOfEventRequest
+	(*GetAllNewTasksOfEventResponse)(nil),     // 10: GetAllNewTasksOfEventResponse
+	(*GetNewTaskResponse)(nil),                // 11: GetNewTaskResponse
+	(*CloseTaskRequest)(nil),                  // 12: CloseTaskRequest
+	(*SendCrossCheckReportMatches)(nil),       // 13: SendCrossCheckReportMatches
+	(*SendCrossCheckReportRequest)(nil),       // 14: SendCrossCheckReportRequest
+	(*SendDefaultReportSegment)(nil),          // 15: SendDefaultReportSegment
+	(*SendDefaultReportRequest)(nil),          // 16: SendDefaultReportRequest
+	(*TaskNotification)(nil),                  // 17: TaskNotification
+	(*ExtendTaskLeaseRequest)(nil),            // 18: ExtendTaskLeaseRequest
+	(*ExtendTaskLeaseResponse)(nil),           // 19: ExtendTaskLeaseResponse
+	(*CheckerEngine)(nil),                     // 20: CheckerEngine
+	(*RegisterRunnerCapabilitiesRequest)(nil), // 21: RegisterRunnerCapabilitiesRequest
+	(*emptypb.Empty)(nil),                     // 22: google.protobuf.Empty
 }
 var file_orchestrator_proto_depIdxs = []int32{
-	1,  // 0: GetRunnerInfoResponse.runner:type_name -> Runner
-	6,  // 1: GetWorksDownloadLinksResponse.item:type_name -> GetWorksDownloadLinksResponseItem
-	0,  // 2: GetAllNewTasksOfEventResponse.task:type_name -> Task
-	0,  // 3: GetNewTaskResponse.task:type_name -> Task
-	12, // 4: SendCrossCheckReportRequest.match:type_name -> SendCrossCheckReportMatches
-	14, // 5: SendDefaultReportRequest.segment:type_name -> SendDefaultReportSegment
-	16, // 6: Orchestrator.GetRunnerInfo:input_type -> google.protobuf.Empty
-	16, // 7: Orchestrator.GetNewTask:input_type -> google.protobuf.Empty
-	8,  // 8: Orchestrator.GetAllNewTasksOfEvent:input_type -> GetAllNewTasksOfEventRequest
-	11, // 9: Orchestrator.CloseTask:input_type -> CloseTaskRequest
-	11, // 10: Orchestrator.CloseTaskWithError:input_type -> CloseTaskRequest
-	3,  // 11: Orchestrator.GetWorksOfEvent:input_type -> GetWorksOfEventRequest
-	5,  // 12: Orchestrator.GetWorksDownloadLinks:input_type -> GetWorksDownloadLinksRequest
-	13, // 13: Orchestrator.SendCrossCheckReport:input_type -> SendCrossCheckReportRequest
-	15, // 14: Orchestrator.SendDefaultReport:input_type -> SendDefaultReportRequest
-	2,  // 15: Orchestrator.GetRunnerInfo:output_type -> GetRunnerInfoResponse
-	10, // 16: Orchestrator.GetNewTask:output_type -> GetNewTaskResponse
-	9,  // 17: Orchestrator.GetAllNewTasksOfEvent:output_type -> GetAllNewTasksOfEventResponse
-	16, // 18: Orchestrator.CloseTask:output_type -> google.protobuf.Empty
-	16, // 19: Orchestrator.CloseTaskWithError:output_type -> google.protobuf.Empty
-	4,  // 20: Orchestrator.GetWorksOfEvent:output_type -> GetWorksOfEventResponse
-	7,  // 21: Orchestrator.GetWorksDownloadLinks:output_type -> GetWorksDownloadLinksResponse
-	16, // 22: Orchestrator.SendCrossCheckReport:output_type -> google.protobuf.Empty
-	16, // 23: Orchestrator.SendDefaultReport:output_type -> google.protobuf.Empty
-	15, // [15:24] is the sub-list for method output_type
-	6,  // [6:15] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	2,  // 0: GetRunnerInfoResponse.runner:type_name -> Runner
+	7,  // 1: GetWorksDownloadLinksResponse.item:type_name -> GetWorksDownloadLinksResponseItem
+	1,  // 2: GetAllNewTasksOfEventResponse.task:type_name -> Task
+	1,  // 3: GetNewTaskResponse.task:type_name -> Task
+	13, // 4: SendCrossCheckReportRequest.match:type_name -> SendCrossCheckReportMatches
+	0,  // 5: SendCrossCheckReportRequest.offsetUnit:type_name -> OffsetUnit
+	15, // 6: SendDefaultReportRequest.segment:type_name -> SendDefaultReportSegment
+	20, // 7: RegisterRunnerCapabilitiesRequest.engine:type_name -> CheckerEngine
+	0,  // 8: RegisterRunnerCapabilitiesRequest.offsetUnit:type_name -> OffsetUnit
+	22, // 9: Orchestrator.GetRunnerInfo:input_type -> google.protobuf.Empty
+	22, // 10: Orchestrator.GetNewTask:input_type -> google.protobuf.Empty
+	9,  // 11: Orchestrator.GetAllNewTasksOfEvent:input_type -> GetAllNewTasksOfEventRequest
+	12, // 12: Orchestrator.CloseTask:input_type -> CloseTaskRequest
+	12, // 13: Orchestrator.CloseTaskWithError:input_type -> CloseTaskRequest
+	4,  // 14: Orchestrator.GetWorksOfEvent:input_type -> GetWorksOfEventRequest
+	6,  // 15: Orchestrator.GetWorksDownloadLinks:input_type -> GetWorksDownloadLinksRequest
+	14, // 16: Orchestrator.SendCrossCheckReport:input_type -> SendCrossCheckReportRequest
+	16, // 17: Orchestrator.SendDefaultReport:input_type -> SendDefaultReportRequest
+	22, // 18: Orchestrator.SubscribeTasks:input_type -> google.protobuf.Empty
+	18, // 19: Orchestrator.ExtendTaskLease:input_type -> ExtendTaskLeaseRequest
+	21, // 20: Orchestrator.RegisterRunnerCapabilities:input_type -> RegisterRunnerCapabilitiesRequest
+	3,  // 21: Orchestrator.GetRunnerInfo:output_type -> GetRunnerInfoResponse
+	11, // 22: Orchestrator.GetNewTask:output_type -> GetNewTaskResponse
+	10, // 23: Orchestrator.GetAllNewTasksOfEvent:output_type -> GetAllNewTasksOfEventResponse
+	22, // 24: Orchestrator.CloseTask:output_type -> google.protobuf.Empty
+	22, // 25: Orchestrator.CloseTaskWithError:output_type -> google.protobuf.Empty
+	5,  // 26: Orchestrator.GetWorksOfEvent:output_type -> GetWorksOfEventResponse
+	8,  // 27: Orchestrator.GetWorksDownloadLinks:output_type -> GetWorksDownloadLinksResponse
+	22, // 28: Orchestrator.SendCrossCheckReport:output_type -> google.protobuf.Empty
+	22, // 29: Orchestrator.SendDefaultReport:output_type -> google.protobuf.Empty
+	17, // 30: Orchestrator.SubscribeTasks:output_type -> TaskNotification
+	19, // 31: Orchestrator.ExtendTaskLease:output_type -> ExtendTaskLeaseResponse
+	22, // 32: Orchestrator.RegisterRunnerCapabilities:output_type -> google.protobuf.Empty
+	21, // [21:33] is the sub-list for method output_type
+	9,  // [9:21] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_orchestrator_proto_init() }
@@ -1083,18 +1519,20 @@ func file_orchestrator_proto_init() {
 	if File_orchestrator_proto != nil {
 		return
 	}
+	file_orchestrator_proto_msgTypes[12].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_orchestrator_proto_rawDesc), len(file_orchestrator_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   16,
+			NumEnums:      1,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_orchestrator_proto_goTypes,
 		DependencyIndexes: file_orchestrator_proto_depIdxs,
+		EnumInfos:         file_orchestrator_proto_enumTypes,
 		MessageInfos:      file_orchestrator_proto_msgTypes,
 	}.Build()
 	File_orchestrator_proto = out.File
@@ -20,15 +20,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Orchestrator_GetRunnerInfo_FullMethodName         = "/Orchestrator/GetRunnerInfo"
-	Orchestrator_GetNewTask_FullMethodName            = "/Orchestrator/GetNewTask"
-	Orchestrator_GetAllNewTasksOfEvent_FullMethodName = "/Orchestrator/GetAllNewTasksOfEvent"
-	Orchestrator_CloseTask_FullMethodName             = "/Orchestrator/CloseTask"
-	Orchestrator_CloseTaskWithError_FullMethodName    = "/Orchestrator/CloseTaskWithError"
-	Orchestrator_GetWorksOfEvent_FullMethodName       = "/Orchestrator/GetWorksOfEvent"
-	Orchestrator_GetWorksDownloadLinks_FullMethodName = "/Orchestrator/GetWorksDownloadLinks"
-	Orchestrator_SendCrossCheckReport_FullMethodName  = "/Orchestrator/SendCrossCheckReport"
-	Orchestrator_SendDefaultReport_FullMethodName     = "/Orchestrator/SendDefaultReport"
+	Orchestrator_GetRunnerInfo_FullMethodName              = "/Orchestrator/GetRunnerInfo"
+	Orchestrator_GetNewTask_FullMethodName                 = "/Orchestrator/GetNewTask"
+	Orchestrator_GetAllNewTasksOfEvent_FullMethodName      = "/Orchestrator/GetAllNewTasksOfEvent"
+	Orchestrator_CloseTask_FullMethodName                  = "/Orchestrator/CloseTask"
+	Orchestrator_CloseTaskWithError_FullMethodName         = "/Orchestrator/CloseTaskWithError"
+	Orchestrator_GetWorksOfEvent_FullMethodName            = "/Orchestrator/GetWorksOfEvent"
+	Orchestrator_GetWorksDownloadLinks_FullMethodName      = "/Orchestrator/GetWorksDownloadLinks"
+	Orchestrator_SendCrossCheckReport_FullMethodName       = "/Orchestrator/SendCrossCheckReport"
+	Orchestrator_SendDefaultReport_FullMethodName          = "/Orchestrator/SendDefaultReport"
+	Orchestrator_SubscribeTasks_FullMethodName             = "/Orchestrator/SubscribeTasks"
+	Orchestrator_ExtendTaskLease_FullMethodName            = "/Orchestrator/ExtendTaskLease"
+	Orchestrator_RegisterRunnerCapabilities_FullMethodName = "/Orchestrator/RegisterRunnerCapabilities"
 )
 
 // OrchestratorClient is the client API for Orchestrator service.
@@ -44,6 +47,9 @@ type OrchestratorClient interface {
 	GetWorksDownloadLinks(ctx context.Context, in *GetWorksDownloadLinksRequest, opts ...grpc.CallOption) (*GetWorksDownloadLinksResponse, error)
 	SendCrossCheckReport(ctx context.Context, in *SendCrossCheckReportRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	SendDefaultReport(ctx context.Context, in *SendDefaultReportRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SubscribeTasks(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskNotification], error)
+	ExtendTaskLease(ctx context.Context, in *ExtendTaskLeaseRequest, opts ...grpc.CallOption) (*ExtendTaskLeaseResponse, error)
+	RegisterRunnerCapabilities(ctx context.Context, in *RegisterRunnerCapabilitiesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type orchestratorClient struct {
@@ -144,6 +150,45 @@ func (c *orchestratorClient) SendDefaultReport(ctx context.Context, in *SendDefa
 	return out, nil
 }
 
+func (c *orchestratorClient) SubscribeTasks(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskNotification], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Orchestrator_ServiceDesc.Streams[0], Orchestrator_SubscribeTasks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, TaskNotification]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Orchestrator_SubscribeTasksClient = grpc.ServerStreamingClient[TaskNotification]
+
+func (c *orchestratorClient) ExtendTaskLease(ctx context.Context, in *ExtendTaskLeaseRequest, opts ...grpc.CallOption) (*ExtendTaskLeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExtendTaskLeaseResponse)
+	err := c.cc.Invoke(ctx, Orchestrator_ExtendTaskLease_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorClient) RegisterRunnerCapabilities(ctx context.Context, in *RegisterRunnerCapabilitiesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Orchestrator_RegisterRunnerCapabilities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrchestratorServer is the server API for Orchestrator service.
 // All implementations must embed UnimplementedOrchestratorServer
 // for forward compatibility.
@@ -157,6 +202,9 @@ type OrchestratorServer interface {
 	GetWorksDownloadLinks(context.Context, *GetWorksDownloadLinksRequest) (*GetWorksDownloadLinksResponse, error)
 	SendCrossCheckReport(context.Context, *SendCrossCheckReportRequest) (*emptypb.Empty, error)
 	SendDefaultReport(context.Context, *SendDefaultReportRequest) (*emptypb.Empty, error)
+	SubscribeTasks(*emptypb.Empty, grpc.ServerStreamingServer[TaskNotification]) error
+	ExtendTaskLease(context.Context, *ExtendTaskLeaseRequest) (*ExtendTaskLeaseResponse, error)
+	RegisterRunnerCapabilities(context.Context, *RegisterRunnerCapabilitiesRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedOrchestratorServer()
 }
 
@@ -194,6 +242,15 @@ func (UnimplementedOrchestratorServer) SendCrossCheckReport(context.Context, *Se
 func (UnimplementedOrchestratorServer) SendDefaultReport(context.Context, *SendDefaultReportRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendDefaultReport not implemented")
 }
+func (UnimplementedOrchestratorServer) SubscribeTasks(*emptypb.Empty, grpc.ServerStreamingServer[TaskNotification]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTasks not implemented")
+}
+func (UnimplementedOrchestratorServer) ExtendTaskLease(context.Context, *ExtendTaskLeaseRequest) (*ExtendTaskLeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendTaskLease not implemented")
+}
+func (UnimplementedOrchestratorServer) RegisterRunnerCapabilities(context.Context, *RegisterRunnerCapabilitiesRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterRunnerCapabilities not implemented")
+}
 func (UnimplementedOrchestratorServer) mustEmbedUnimplementedOrchestratorServer() {}
 func (UnimplementedOrchestratorServer) testEmbeddedByValue()                      {}
 
@@ -377,6 +434,53 @@ func _Orchestrator_SendDefaultReport_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Orchestrator_SubscribeTasks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrchestratorServer).SubscribeTasks(m, &grpc.GenericServerStream[emptypb.Empty, TaskNotification]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Orchestrator_SubscribeTasksServer = grpc.ServerStreamingServer[TaskNotification]
+
+func _Orchestrator_ExtendTaskLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendTaskLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServer).ExtendTaskLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Orchestrator_ExtendTaskLease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServer).ExtendTaskLease(ctx, req.(*ExtendTaskLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Orchestrator_RegisterRunnerCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRunnerCapabilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServer).RegisterRunnerCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Orchestrator_RegisterRunnerCapabilities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServer).RegisterRunnerCapabilities(ctx, req.(*RegisterRunnerCapabilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Orchestrator_ServiceDesc is the grpc.ServiceDesc for Orchestrator service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -420,7 +524,21 @@ var Orchestrator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendDefaultReport",
 			Handler:    _Orchestrator_SendDefaultReport_Handler,
 		},
+		{
+			MethodName: "ExtendTaskLease",
+			Handler:    _Orchestrator_ExtendTaskLease_Handler,
+		},
+		{
+			MethodName: "RegisterRunnerCapabilities",
+			Handler:    _Orchestrator_RegisterRunnerCapabilities_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeTasks",
+			Handler:       _Orchestrator_SubscribeTasks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "orchestrator.proto",
 }